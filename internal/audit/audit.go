@@ -0,0 +1,203 @@
+// Package audit matches Java versions against a feed of known vulnerabilities,
+// such as the OpenJDK vulnerability advisories converted to JSON.
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"jv/internal/java"
+)
+
+// cacheFile holds the last feed fetched from a URL, used when it cannot be reached
+const cacheFile = "vulnerabilities.json"
+
+// Feed is a list of vulnerabilities with the releases that fix them
+type Feed struct {
+	Updated         string          `json:"updated,omitempty"`
+	Vulnerabilities []Vulnerability `json:"vulnerabilities"`
+
+	Source   string    `json:"-"` // URL or file the feed was read from
+	CachedAt time.Time `json:"-"` // When the copy in use was fetched, if the source could not be reached
+}
+
+// Vulnerability is one advisory entry. Fixed maps every affected feature
+// release to the first version that fixes it, e.g. "21": "21.0.5"; an empty
+// version means the release is affected and gets no fix.
+type Vulnerability struct {
+	ID        string            `json:"id"`                  // CVE identifier
+	Advisory  string            `json:"advisory,omitempty"`  // Date of the advisory, e.g. "2024-10-15"
+	Component string            `json:"component,omitempty"` // Affected component, e.g. "hotspot/compiler"
+	Score     float64           `json:"score,omitempty"`     // CVSS base score
+	Severity  string            `json:"severity,omitempty"`  // Derived from Score when missing
+	URL       string            `json:"url,omitempty"`
+	Fixed     map[string]string `json:"fixed"`
+}
+
+// Severities lists the severity levels from the most to the least severe
+var Severities = []string{"critical", "high", "medium", "low", "none"}
+
+// SeverityRank orders severities, 0 being the most severe. Unknown
+// severities rank below every known one.
+func SeverityRank(severity string) int {
+	for rank, known := range Severities {
+		if strings.EqualFold(known, severity) {
+			return rank
+		}
+	}
+	return len(Severities)
+}
+
+// Fails reports whether a finding of severity fails an audit that fails on
+// threshold and above. The threshold "none" only reports and never fails.
+func Fails(severity string, threshold string) bool {
+	if strings.EqualFold(threshold, "none") {
+		return false
+	}
+	return SeverityRank(severity) <= SeverityRank(threshold)
+}
+
+// Level returns the severity, derived from the CVSS score when the feed does
+// not name one
+func (v Vulnerability) Level() string {
+	if v.Severity != "" {
+		return strings.ToLower(v.Severity)
+	}
+	switch {
+	case v.Score >= 9:
+		return "critical"
+	case v.Score >= 7:
+		return "high"
+	case v.Score >= 4:
+		return "medium"
+	case v.Score > 0:
+		return "low"
+	}
+	return "none"
+}
+
+// Finding is a vulnerability affecting a particular version
+type Finding struct {
+	Vulnerability
+	FixedIn string // First fixed version, "" if there is none for the release
+}
+
+// Check returns the vulnerabilities affecting version, the most severe first
+func (f *Feed) Check(version string) []Finding {
+	parsed := java.ParseVersion(version)
+	if len(parsed) == 0 {
+		return nil
+	}
+	major := strconv.Itoa(parsed[0])
+
+	var findings []Finding
+	for _, vuln := range f.Vulnerabilities {
+		fixed, ok := vuln.Fixed[major]
+		if !ok {
+			continue
+		}
+		if fixed != "" && java.CompareParsedVersions(parsed, java.ParseVersion(fixed)) >= 0 {
+			continue
+		}
+		findings = append(findings, Finding{Vulnerability: vuln, FixedIn: fixed})
+	}
+
+	sort.SliceStable(findings, func(a, b int) bool {
+		left, right := SeverityRank(findings[a].Level()), SeverityRank(findings[b].Level())
+		if left != right {
+			return left < right
+		}
+		return findings[a].Score > findings[b].Score
+	})
+	return findings
+}
+
+// Load reads the feed from source, an http(s) URL or a local file. A feed
+// fetched from a URL is kept in cacheDir; when the URL cannot be reached that
+// copy is used instead and CachedAt tells its age.
+func Load(ctx context.Context, source string, cacheDir string) (*Feed, error) {
+	if !strings.HasPrefix(source, "http://") && !strings.HasPrefix(source, "https://") {
+		raw, err := os.ReadFile(source)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read vulnerability feed: %w", err)
+		}
+		return parse(raw, source)
+	}
+
+	cachePath := filepath.Join(cacheDir, cacheFile)
+	raw, fetchErr := fetch(ctx, source)
+	if fetchErr == nil {
+		feed, err := parse(raw, source)
+		if err != nil {
+			return nil, err
+		}
+		if err := os.MkdirAll(cacheDir, 0755); err == nil {
+			tmpPath := cachePath + ".tmp"
+			if err := os.WriteFile(tmpPath, raw, 0644); err == nil {
+				os.Rename(tmpPath, cachePath)
+			}
+		}
+		return feed, nil
+	}
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	info, err := os.Stat(cachePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch vulnerability feed: %w", fetchErr)
+	}
+	raw, err = os.ReadFile(cachePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch vulnerability feed: %w", fetchErr)
+	}
+	feed, err := parse(raw, source)
+	if err != nil {
+		return nil, errors.Join(fmt.Errorf("failed to fetch vulnerability feed: %w", fetchErr), err)
+	}
+	feed.CachedAt = info.ModTime()
+	return feed, nil
+}
+
+// fetch downloads the feed from url
+func fetch(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("server returned status %d", resp.StatusCode)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// parse decodes a feed and checks that every entry can be matched
+func parse(raw []byte, source string) (*Feed, error) {
+	var feed Feed
+	if err := json.Unmarshal(raw, &feed); err != nil {
+		return nil, fmt.Errorf("failed to parse vulnerability feed: %w", err)
+	}
+	for _, vuln := range feed.Vulnerabilities {
+		if vuln.ID == "" || len(vuln.Fixed) == 0 {
+			return nil, fmt.Errorf("invalid vulnerability feed: every entry needs an id and fixed versions")
+		}
+	}
+	feed.Source = source
+	return &feed, nil
+}
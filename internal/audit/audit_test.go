@@ -0,0 +1,88 @@
+package audit
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCheck(t *testing.T) {
+	feed := &Feed{Vulnerabilities: []Vulnerability{
+		{ID: "CVE-A", Score: 7.5, Fixed: map[string]string{"21": "21.0.5", "8": "8u432"}},
+		{ID: "CVE-B", Severity: "Critical", Fixed: map[string]string{"21": "21.0.4", "17": ""}},
+		{ID: "CVE-C", Fixed: map[string]string{"22": ""}},
+		{ID: "CVE-D", Score: 3.1, Fixed: map[string]string{"21": "21.0.5+11"}},
+	}}
+
+	tests := []struct {
+		version string
+		want    []string // IDs, the most severe first
+		fixedIn []string
+	}{
+		{"21.0.3+9", []string{"CVE-B", "CVE-A", "CVE-D"}, []string{"21.0.4", "21.0.5", "21.0.5+11"}},
+		{"21.0.4+7", []string{"CVE-A", "CVE-D"}, []string{"21.0.5", "21.0.5+11"}},
+		{"21.0.5+11", nil, nil},
+		{"21.0.5+10", nil, nil}, // Build numbers are ignored
+		{"1.8.0_422", []string{"CVE-A"}, []string{"8u432"}},
+		{"8u422-b05", []string{"CVE-A"}, []string{"8u432"}},
+		{"1.8.0_432", nil, nil},
+		{"8u442-b06", nil, nil},
+		{"17.0.12+7", []string{"CVE-B"}, []string{""}}, // No fix for the release
+		{"22.0.2", []string{"CVE-C"}, []string{""}},
+		{"11.0.24", nil, nil}, // Not an affected release
+		{"", nil, nil},
+	}
+	for _, tt := range tests {
+		var ids, fixedIn []string
+		for _, finding := range feed.Check(tt.version) {
+			ids = append(ids, finding.ID)
+			fixedIn = append(fixedIn, finding.FixedIn)
+		}
+		if !reflect.DeepEqual(ids, tt.want) || !reflect.DeepEqual(fixedIn, tt.fixedIn) {
+			t.Errorf("Check(%q) = %v fixed in %q, want %v fixed in %q", tt.version, ids, fixedIn, tt.want, tt.fixedIn)
+		}
+	}
+}
+
+func TestLevel(t *testing.T) {
+	tests := []struct {
+		vuln Vulnerability
+		want string
+	}{
+		{Vulnerability{Severity: "HIGH", Score: 2}, "high"},
+		{Vulnerability{Score: 9.8}, "critical"},
+		{Vulnerability{Score: 7}, "high"},
+		{Vulnerability{Score: 4}, "medium"},
+		{Vulnerability{Score: 0.1}, "low"},
+		{Vulnerability{}, "none"},
+	}
+	for _, tt := range tests {
+		if got := tt.vuln.Level(); got != tt.want {
+			t.Errorf("%+v.Level() = %q, want %q", tt.vuln, got, tt.want)
+		}
+	}
+}
+
+func TestFails(t *testing.T) {
+	tests := []struct {
+		severity  string
+		threshold string
+		want      bool
+	}{
+		{"critical", "low", true},
+		{"low", "low", true},
+		{"none", "low", false},
+		{"medium", "high", false},
+		{"high", "high", true},
+		{"Critical", "HIGH", true},
+		{"unknown", "low", false},
+		// none only reports
+		{"critical", "none", false},
+		{"none", "none", false},
+		{"unknown", "none", false},
+	}
+	for _, tt := range tests {
+		if got := Fails(tt.severity, tt.threshold); got != tt.want {
+			t.Errorf("Fails(%q, %q) = %v, want %v", tt.severity, tt.threshold, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+// Package certs reads and writes the truststores of JDKs so that extra CA
+// certificates can be trusted without calling keytool.
+package certs
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+)
+
+// CACertsPath returns the truststore of the JDK at jdkPath: lib/security/cacerts,
+// or jre/lib/security/cacerts in Java 8 JDKs
+func CACertsPath(jdkPath string) (string, error) {
+	for _, candidate := range []string{
+		filepath.Join(jdkPath, "lib", "security", "cacerts"),
+		filepath.Join(jdkPath, "jre", "lib", "security", "cacerts"),
+	} {
+		if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+			return candidate, nil
+		}
+	}
+	return "", fmt.Errorf("no cacerts truststore found in %s", jdkPath)
+}
+
+// ReadPEMFile returns the certificates in a PEM file; other blocks such as
+// private keys are ignored
+func ReadPEMFile(path string) ([]*x509.Certificate, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var certs []*x509.Certificate
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("invalid certificate in %s: %w", path, err)
+		}
+		certs = append(certs, cert)
+	}
+
+	if len(certs) == 0 {
+		return nil, fmt.Errorf("no certificates found in %s", path)
+	}
+	return certs, nil
+}
+
+// Alias returns the alias a certificate is imported under: "jv-" followed by
+// its common name (or organization) in lower case, e.g. "jv-acme-root-ca"
+func Alias(cert *x509.Certificate) string {
+	name := cert.Subject.CommonName
+	if name == "" && len(cert.Subject.Organization) > 0 {
+		name = cert.Subject.Organization[0]
+	}
+	if name == "" {
+		name = cert.SerialNumber.Text(16)
+	}
+
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+			dash = false
+		} else if !dash && b.Len() > 0 {
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+	return "jv-" + strings.TrimSuffix(b.String(), "-")
+}
+
+// Import adds certs to the truststore of the JDK at jdkPath and returns how
+// many of them were not trusted yet. The truststore is only rewritten when
+// something was added.
+func Import(jdkPath string, certs []*x509.Certificate, password string) (int, error) {
+	path, err := CACertsPath(jdkPath)
+	if err != nil {
+		return 0, err
+	}
+
+	ks, err := Load(path, password)
+	if errors.Is(err, ErrIncorrectPassword) {
+		return 0, fmt.Errorf("%s: %w (set truststore_password in the config)", path, err)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	added := 0
+	for _, cert := range certs {
+		if ks.Add(Alias(cert), cert) {
+			added++
+		}
+	}
+	if added == 0 {
+		return 0, nil
+	}
+
+	if err := ks.Save(path, password); err != nil {
+		return 0, fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return added, nil
+}
@@ -0,0 +1,237 @@
+package certs
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"crypto/x509"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"time"
+	"unicode/utf16"
+)
+
+const (
+	jksMagic   = 0xFEEDFEED
+	jceksMagic = 0xCECECECE
+
+	jksPrivateKeyTag  = 1
+	jksTrustedCertTag = 2
+
+	// jksWhitener is hashed between the password and the content to form the
+	// integrity check of JKS keystores
+	jksWhitener = "Mighty Aphrodite"
+)
+
+// jksEntry is an entry jv does not interpret, kept in its encoded form
+type jksEntry struct {
+	alias string
+	raw   []byte
+}
+
+// jksReader reads the big-endian fields of a JKS keystore, remembering the first error
+type jksReader struct {
+	data []byte
+	pos  int
+	err  error
+}
+
+func (r *jksReader) bytes(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if n < 0 || n > len(r.data)-r.pos {
+		r.err = errors.New("keystore is truncated")
+		return nil
+	}
+	b := r.data[r.pos : r.pos+n]
+	r.pos += n
+	return b
+}
+
+func (r *jksReader) uint32() uint32 {
+	if b := r.bytes(4); b != nil {
+		return binary.BigEndian.Uint32(b)
+	}
+	return 0
+}
+
+func (r *jksReader) uint64() uint64 {
+	if b := r.bytes(8); b != nil {
+		return binary.BigEndian.Uint64(b)
+	}
+	return 0
+}
+
+func (r *jksReader) utf() string {
+	var length [2]byte
+	copy(length[:], r.bytes(2))
+	return decodeModifiedUTF8(r.bytes(int(binary.BigEndian.Uint16(length[:]))))
+}
+
+// decodeJKS parses a JKS keystore (versions 1 and 2)
+func decodeJKS(data []byte, password string) (*Keystore, error) {
+	if len(data) < 12+sha1.Size {
+		return nil, errors.New("keystore is truncated")
+	}
+	content, digest := data[:len(data)-sha1.Size], data[len(data)-sha1.Size:]
+	if password != "" && !bytes.Equal(jksDigest(content, password), digest) {
+		return nil, ErrIncorrectPassword
+	}
+
+	r := &jksReader{data: content, pos: 4}
+	ks := &Keystore{Format: FormatJKS, jksVersion: r.uint32()}
+	if ks.jksVersion != 1 && ks.jksVersion != 2 {
+		return nil, fmt.Errorf("unsupported JKS version %d", ks.jksVersion)
+	}
+
+	count := r.uint32()
+	for n := uint32(0); n < count && r.err == nil; n++ {
+		start := r.pos
+		tag := r.uint32()
+		alias := r.utf()
+		date := int64(r.uint64())
+
+		switch tag {
+		case jksPrivateKeyTag:
+			r.bytes(int(r.uint32()))
+			chain := r.uint32()
+			for c := uint32(0); c < chain && r.err == nil; c++ {
+				if ks.jksVersion == 2 {
+					r.utf()
+				}
+				r.bytes(int(r.uint32()))
+			}
+			ks.jksOther = append(ks.jksOther, jksEntry{alias: alias, raw: content[start:r.pos]})
+
+		case jksTrustedCertTag:
+			certType := "X.509"
+			if ks.jksVersion == 2 {
+				certType = r.utf()
+			}
+			der := r.bytes(int(r.uint32()))
+			if r.err != nil {
+				break
+			}
+			cert, err := x509.ParseCertificate(der)
+			if certType != "X.509" || err != nil {
+				// Keep what Go cannot parse so that writing the keystore loses nothing
+				ks.jksOther = append(ks.jksOther, jksEntry{alias: alias, raw: content[start:r.pos]})
+				continue
+			}
+			ks.Entries = append(ks.Entries, Entry{
+				Alias:       alias,
+				Certificate: cert,
+				Date:        time.UnixMilli(date),
+				raw:         content[start:r.pos],
+			})
+
+		default:
+			return nil, fmt.Errorf("unknown JKS entry type %d", tag)
+		}
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+	if r.pos != len(content) {
+		return nil, errors.New("unexpected data after the last keystore entry")
+	}
+	return ks, nil
+}
+
+// encodeJKS writes the keystore in the JKS version it was read with
+func (k *Keystore) encodeJKS(password string) ([]byte, error) {
+	version := k.jksVersion
+	if version == 0 {
+		version = 2
+	}
+
+	var buf bytes.Buffer
+	write := func(v interface{}) { binary.Write(&buf, binary.BigEndian, v) }
+	writeUTF := func(s string) error {
+		encoded := encodeModifiedUTF8(s)
+		if len(encoded) > 0xFFFF {
+			return fmt.Errorf("alias %q is too long", s)
+		}
+		write(uint16(len(encoded)))
+		buf.Write(encoded)
+		return nil
+	}
+
+	write(uint32(jksMagic))
+	write(version)
+	write(uint32(len(k.jksOther) + len(k.Entries)))
+	for _, entry := range k.jksOther {
+		buf.Write(entry.raw)
+	}
+	for _, entry := range k.Entries {
+		if entry.raw != nil {
+			buf.Write(entry.raw)
+			continue
+		}
+		write(uint32(jksTrustedCertTag))
+		if err := writeUTF(entry.Alias); err != nil {
+			return nil, err
+		}
+		write(entry.Date.UnixMilli())
+		if version == 2 {
+			writeUTF("X.509")
+		}
+		write(uint32(len(entry.Certificate.Raw)))
+		buf.Write(entry.Certificate.Raw)
+	}
+
+	buf.Write(jksDigest(buf.Bytes(), password))
+	return buf.Bytes(), nil
+}
+
+// jksDigest computes the integrity check of JKS content: SHA-1 over the
+// password as UTF-16BE, the whitener and the content
+func jksDigest(content []byte, password string) []byte {
+	h := sha1.New()
+	for _, unit := range utf16.Encode([]rune(password)) {
+		h.Write([]byte{byte(unit >> 8), byte(unit)})
+	}
+	h.Write([]byte(jksWhitener))
+	h.Write(content)
+	return h.Sum(nil)
+}
+
+// encodeModifiedUTF8 encodes s like Java's DataOutput.writeUTF: UTF-16 code
+// units in one to three bytes each, with NUL as two bytes
+func encodeModifiedUTF8(s string) []byte {
+	var out []byte
+	for _, unit := range utf16.Encode([]rune(s)) {
+		switch {
+		case unit >= 0x01 && unit <= 0x7F:
+			out = append(out, byte(unit))
+		case unit <= 0x7FF:
+			out = append(out, 0xC0|byte(unit>>6), 0x80|byte(unit&0x3F))
+		default:
+			out = append(out, 0xE0|byte(unit>>12), 0x80|byte(unit>>6&0x3F), 0x80|byte(unit&0x3F))
+		}
+	}
+	return out
+}
+
+// decodeModifiedUTF8 reverses encodeModifiedUTF8
+func decodeModifiedUTF8(b []byte) string {
+	units := make([]uint16, 0, len(b))
+	for i := 0; i < len(b); {
+		switch c := b[i]; {
+		case c < 0x80:
+			units = append(units, uint16(c))
+			i++
+		case c&0xE0 == 0xC0 && i+1 < len(b):
+			units = append(units, uint16(c&0x1F)<<6|uint16(b[i+1]&0x3F))
+			i += 2
+		case c&0xF0 == 0xE0 && i+2 < len(b):
+			units = append(units, uint16(c&0x0F)<<12|uint16(b[i+1]&0x3F)<<6|uint16(b[i+2]&0x3F))
+			i += 3
+		default:
+			units = append(units, 0xFFFD)
+			i++
+		}
+	}
+	return string(utf16.Decode(units))
+}
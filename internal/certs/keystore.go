@@ -0,0 +1,167 @@
+package certs
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Format is the file format of a Java keystore
+type Format string
+
+const (
+	FormatJKS    Format = "JKS"    // Sun keystore, used for cacerts up to JDK 17
+	FormatPKCS12 Format = "PKCS12" // Used for cacerts since JDK 18, without a password
+)
+
+// ErrIncorrectPassword is returned when a keystore's integrity check fails
+var ErrIncorrectPassword = errors.New("keystore password was incorrect")
+
+// Entry is a trusted certificate in a keystore
+type Entry struct {
+	Alias       string
+	Certificate *x509.Certificate
+	Date        time.Time // Creation date, zero for PKCS12 keystores which do not record it
+
+	raw []byte // Encoded entry as read, reused when the keystore is written back
+}
+
+// Keystore is a Java keystore. Only trusted certificate entries are exposed;
+// private keys and content jv cannot read (such as encrypted PKCS12 bags) are
+// kept as they are and written back unchanged.
+type Keystore struct {
+	Format  Format
+	Entries []Entry
+
+	jksVersion uint32
+	jksOther   []jksEntry
+
+	p12Other  [][]byte // Safe bags that are not trusted certificates
+	p12Sealed [][]byte // Encrypted content infos
+	p12Mac    *p12MacParams
+}
+
+// Load reads a keystore file. password is checked against the keystore's
+// integrity data, which password-less PKCS12 keystores do not have.
+func Load(path string, password string) (*Keystore, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Decode(data, password)
+}
+
+// Decode parses a JKS or PKCS12 keystore, detected from its content
+func Decode(data []byte, password string) (*Keystore, error) {
+	if len(data) < 4 {
+		return nil, errors.New("keystore is empty or truncated")
+	}
+	switch binary.BigEndian.Uint32(data) {
+	case jksMagic:
+		return decodeJKS(data, password)
+	case jceksMagic:
+		return nil, errors.New("JCEKS keystores are not supported")
+	}
+	if data[0] == 0x30 {
+		return decodePKCS12(data, password)
+	}
+	return nil, errors.New("unknown keystore format")
+}
+
+// Encode serializes the keystore in its original format
+func (k *Keystore) Encode(password string) ([]byte, error) {
+	switch k.Format {
+	case FormatJKS:
+		return k.encodeJKS(password)
+	case FormatPKCS12:
+		return k.encodePKCS12(password)
+	}
+	return nil, fmt.Errorf("unknown keystore format %q", k.Format)
+}
+
+// Save writes the keystore to path by replacing the file, so that a failed
+// write never leaves a truncated truststore behind. Symbolic links are followed.
+func (k *Keystore) Save(path string, password string) error {
+	data, err := k.Encode(password)
+	if err != nil {
+		return err
+	}
+
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), mode); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Contains reports whether cert is one of the trusted certificates
+func (k *Keystore) Contains(cert *x509.Certificate) bool {
+	for _, entry := range k.Entries {
+		if bytes.Equal(entry.Certificate.Raw, cert.Raw) {
+			return true
+		}
+	}
+	return false
+}
+
+// Add adds cert as a trusted certificate under alias, made unique if another
+// entry already uses it. It returns false when cert is already trusted.
+func (k *Keystore) Add(alias string, cert *x509.Certificate) bool {
+	if k.Contains(cert) {
+		return false
+	}
+
+	unique := alias
+	for n := 2; k.hasAlias(unique); n++ {
+		unique = fmt.Sprintf("%s-%d", alias, n)
+	}
+	k.Entries = append(k.Entries, Entry{Alias: unique, Certificate: cert, Date: time.Now()})
+	return true
+}
+
+// hasAlias reports whether an entry uses alias; like the JDK, aliases are
+// compared without regard to case
+func (k *Keystore) hasAlias(alias string) bool {
+	for _, entry := range k.Entries {
+		if strings.EqualFold(entry.Alias, alias) {
+			return true
+		}
+	}
+	for _, entry := range k.jksOther {
+		if strings.EqualFold(entry.alias, alias) {
+			return true
+		}
+	}
+	for _, bag := range k.p12Other {
+		if strings.EqualFold(bagAlias(bag), alias) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,153 @@
+package certs
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+)
+
+var oidSHA256 = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 1}
+
+// newTestCert returns a self-signed CA certificate named name
+func newTestCert(t *testing.T, name string) *x509.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert
+}
+
+// roundTrip encodes ks, decodes it again and checks the trusted aliases
+func roundTrip(t *testing.T, ks *Keystore, password string, aliases ...string) *Keystore {
+	t.Helper()
+	data, err := ks.Encode(password)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := Decode(data, password)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Format != ks.Format {
+		t.Errorf("format = %s, want %s", decoded.Format, ks.Format)
+	}
+	if len(decoded.Entries) != len(aliases) {
+		t.Fatalf("decoded %d entries, want %d", len(decoded.Entries), len(aliases))
+	}
+	for n, alias := range aliases {
+		if decoded.Entries[n].Alias != alias {
+			t.Errorf("entry %d alias = %q, want %q", n, decoded.Entries[n].Alias, alias)
+		}
+	}
+	return decoded
+}
+
+func TestJKSRoundTrip(t *testing.T) {
+	first, second := newTestCert(t, "First CA"), newTestCert(t, "Second CA")
+
+	ks := &Keystore{Format: FormatJKS}
+	ks.Add("first", first)
+	ks = roundTrip(t, ks, "changeit", "first")
+	if !ks.Add("second", second) || ks.Add("again", first) {
+		t.Fatal("Add must add new certificates only")
+	}
+	ks = roundTrip(t, ks, "changeit", "first", "second")
+	if !ks.Contains(first) || !ks.Contains(second) {
+		t.Error("round trip lost a certificate")
+	}
+
+	data, err := ks.Encode("changeit")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Decode(data, "wrong"); !errors.Is(err, ErrIncorrectPassword) {
+		t.Errorf("wrong password: got %v, want ErrIncorrectPassword", err)
+	}
+	data[len(data)/2] ^= 0xFF
+	if _, err := Decode(data, "changeit"); !errors.Is(err, ErrIncorrectPassword) {
+		t.Errorf("corrupted keystore: got %v, want ErrIncorrectPassword", err)
+	}
+}
+
+func TestPKCS12RoundTrip(t *testing.T) {
+	for _, mac := range []*p12MacParams{
+		nil,
+		{digest: pkix.AlgorithmIdentifier{Algorithm: oidSHA256}, iterations: 10000},
+	} {
+		first, second := newTestCert(t, "First CA"), newTestCert(t, "Second CA")
+
+		ks := &Keystore{Format: FormatPKCS12, p12Mac: mac}
+		ks.Add("first", first)
+		ks = roundTrip(t, ks, "changeit", "first")
+		if (ks.p12Mac != nil) != (mac != nil) {
+			t.Fatalf("mac=%v: the MAC was not kept as it was", mac != nil)
+		}
+		ks.Add("second", second)
+		ks = roundTrip(t, ks, "changeit", "first", "second")
+		if !ks.Contains(first) || !ks.Contains(second) {
+			t.Errorf("mac=%v: round trip lost a certificate", mac != nil)
+		}
+
+		data, err := ks.Encode("changeit")
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = Decode(data, "wrong")
+		if mac != nil && !errors.Is(err, ErrIncorrectPassword) {
+			t.Errorf("wrong password: got %v, want ErrIncorrectPassword", err)
+		}
+		if mac == nil && err != nil {
+			t.Errorf("a keystore without a MAC has no password to check, got %v", err)
+		}
+	}
+}
+
+func TestAddAvoidsPrivateKeyAliases(t *testing.T) {
+	cert := newTestCert(t, "Corporate CA")
+
+	// A key bag is kept as is; its content does not matter here
+	name, _ := asn1.Marshal(asn1.RawValue{Tag: asn1.TagBMPString, Bytes: encodeBMPString("Corp")})
+	keyBag, err := asn1.Marshal(p12SafeBag{
+		ID:         asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 12, 10, 1, 1},
+		Value:      asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: []byte{0x05, 0x00}},
+		Attributes: []p12Attribute{{ID: oidFriendlyName, Value: asn1.RawValue{Tag: asn1.TagSet, IsCompound: true, Bytes: name}}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p12 := &Keystore{Format: FormatPKCS12, p12Other: [][]byte{keyBag}}
+	p12.Add("corp", cert)
+	if decoded := roundTrip(t, p12, "", "corp-2"); len(decoded.p12Other) != 1 {
+		t.Errorf("kept %d other bags, want the key bag", len(decoded.p12Other))
+	}
+
+	jks := &Keystore{Format: FormatJKS, jksOther: []jksEntry{{alias: "corp"}}}
+	jks.Add("corp", cert)
+	if jks.Entries[0].Alias != "corp-2" {
+		t.Errorf("alias = %q, want corp-2", jks.Entries[0].Alias)
+	}
+}
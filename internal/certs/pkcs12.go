@@ -0,0 +1,394 @@
+package certs
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"hash"
+	"unicode/utf16"
+)
+
+var (
+	oidData         = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 1}
+	oidCertBag      = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 12, 10, 1, 3}
+	oidX509Cert     = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 22, 1}
+	oidFriendlyName = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 20}
+
+	// The JDK only trusts certificate bags carrying this attribute
+	oidJavaTrustedKeyUsage = asn1.ObjectIdentifier{2, 16, 840, 1, 113894, 746875, 1, 1}
+	oidAnyExtendedKeyUsage = asn1.ObjectIdentifier{2, 5, 29, 37, 0}
+)
+
+// p12MacHashes are the digests accepted for the integrity MAC
+var p12MacHashes = map[string]func() hash.Hash{
+	"1.3.14.3.2.26":          sha1.New,
+	"2.16.840.1.101.3.4.2.1": sha256.New,
+	"2.16.840.1.101.3.4.2.2": sha512.New384,
+	"2.16.840.1.101.3.4.2.3": sha512.New,
+}
+
+type p12PFX struct {
+	Version  int
+	AuthSafe p12ContentInfo
+	MacData  p12MacData `asn1:"optional"`
+}
+
+type p12ContentInfo struct {
+	ContentType asn1.ObjectIdentifier
+	Content     asn1.RawValue `asn1:"tag:0,explicit,optional"`
+}
+
+type p12MacData struct {
+	Mac        p12DigestInfo
+	MacSalt    []byte
+	Iterations int `asn1:"optional,default:1"`
+}
+
+type p12DigestInfo struct {
+	Algorithm pkix.AlgorithmIdentifier
+	Digest    []byte
+}
+
+type p12SafeBag struct {
+	ID         asn1.ObjectIdentifier
+	Value      asn1.RawValue  `asn1:"tag:0,explicit"`
+	Attributes []p12Attribute `asn1:"set,optional"`
+}
+
+type p12Attribute struct {
+	ID    asn1.ObjectIdentifier
+	Value asn1.RawValue `asn1:"set"`
+}
+
+type p12CertBag struct {
+	ID   asn1.ObjectIdentifier
+	Data []byte `asn1:"tag:0,explicit"`
+}
+
+// p12MacParams records how a keystore's MAC was computed so that it can be
+// recomputed the same way when the keystore is written
+type p12MacParams struct {
+	digest     pkix.AlgorithmIdentifier
+	iterations int
+}
+
+// decodePKCS12 parses a PKCS12 keystore. Trusted certificates in encrypted
+// content cannot be read without implementing PBE ciphers and are kept as is.
+func decodePKCS12(data []byte, password string) (*Keystore, error) {
+	var pfx p12PFX
+	if err := unmarshalDER(data, &pfx); err != nil {
+		return nil, fmt.Errorf("invalid PKCS12 keystore: %w", err)
+	}
+	if pfx.Version != 3 {
+		return nil, fmt.Errorf("unsupported PKCS12 version %d", pfx.Version)
+	}
+	if !pfx.AuthSafe.ContentType.Equal(oidData) {
+		return nil, errors.New("PKCS12 keystores protected with a public key are not supported")
+	}
+	authSafe, err := dataContent(pfx.AuthSafe)
+	if err != nil {
+		return nil, fmt.Errorf("invalid PKCS12 keystore: %w", err)
+	}
+
+	ks := &Keystore{Format: FormatPKCS12}
+	if len(pfx.MacData.Mac.Algorithm.Algorithm) > 0 {
+		ks.p12Mac = &p12MacParams{digest: pfx.MacData.Mac.Algorithm, iterations: pfx.MacData.Iterations}
+		mac, err := ks.p12Mac.compute(authSafe, pfx.MacData.MacSalt, password)
+		if err != nil {
+			return nil, err
+		}
+		if !hmac.Equal(mac, pfx.MacData.Mac.Digest) {
+			return nil, ErrIncorrectPassword
+		}
+	}
+
+	var contentInfos []asn1.RawValue
+	if err := unmarshalDER(authSafe, &contentInfos); err != nil {
+		return nil, fmt.Errorf("invalid PKCS12 keystore: %w", err)
+	}
+	for _, raw := range contentInfos {
+		var ci p12ContentInfo
+		if err := unmarshalDER(raw.FullBytes, &ci); err != nil {
+			return nil, fmt.Errorf("invalid PKCS12 keystore: %w", err)
+		}
+		if !ci.ContentType.Equal(oidData) {
+			ks.p12Sealed = append(ks.p12Sealed, raw.FullBytes)
+			continue
+		}
+
+		safeContents, err := dataContent(ci)
+		if err != nil {
+			return nil, fmt.Errorf("invalid PKCS12 keystore: %w", err)
+		}
+		var bags []asn1.RawValue
+		if err := unmarshalDER(safeContents, &bags); err != nil {
+			return nil, fmt.Errorf("invalid PKCS12 keystore: %w", err)
+		}
+		for _, bag := range bags {
+			if entry, ok := trustedEntry(bag.FullBytes); ok {
+				ks.Entries = append(ks.Entries, entry)
+			} else {
+				ks.p12Other = append(ks.p12Other, bag.FullBytes)
+			}
+		}
+	}
+	return ks, nil
+}
+
+// Sealed returns the number of encrypted PKCS12 sections whose entries jv cannot list
+func (k *Keystore) Sealed() int {
+	return len(k.p12Sealed)
+}
+
+// trustedEntry decodes a safe bag holding a certificate trusted by the JDK
+func trustedEntry(der []byte) (Entry, bool) {
+	var bag p12SafeBag
+	if err := unmarshalDER(der, &bag); err != nil || !bag.ID.Equal(oidCertBag) {
+		return Entry{}, false
+	}
+
+	trusted := false
+	for _, attr := range bag.Attributes {
+		if attr.ID.Equal(oidJavaTrustedKeyUsage) {
+			trusted = true
+		}
+	}
+	if !trusted {
+		return Entry{}, false
+	}
+
+	var certBag p12CertBag
+	if err := unmarshalDER(bag.Value.Bytes, &certBag); err != nil || !certBag.ID.Equal(oidX509Cert) {
+		return Entry{}, false
+	}
+	cert, err := x509.ParseCertificate(certBag.Data)
+	if err != nil {
+		return Entry{}, false
+	}
+	return Entry{Alias: friendlyName(bag), Certificate: cert, raw: der}, true
+}
+
+// friendlyName returns the alias of a safe bag, "" if it has none
+func friendlyName(bag p12SafeBag) string {
+	for _, attr := range bag.Attributes {
+		if !attr.ID.Equal(oidFriendlyName) {
+			continue
+		}
+		var name asn1.RawValue
+		if err := unmarshalDER(attr.Value.Bytes, &name); err == nil && name.Tag == asn1.TagBMPString {
+			return decodeBMPString(name.Bytes)
+		}
+	}
+	return ""
+}
+
+// bagAlias returns the alias of an encoded safe bag, "" if it has none
+func bagAlias(der []byte) string {
+	var bag p12SafeBag
+	if err := unmarshalDER(der, &bag); err != nil {
+		return ""
+	}
+	return friendlyName(bag)
+}
+
+// encodePKCS12 writes the keystore with all readable bags in one unencrypted
+// section, after the encrypted sections it was read with. A MAC is added only
+// when the keystore had one, so password-less JDK truststores stay that way.
+func (k *Keystore) encodePKCS12(password string) ([]byte, error) {
+	bags := append([][]byte{}, k.p12Other...)
+	for _, entry := range k.Entries {
+		if entry.raw != nil {
+			bags = append(bags, entry.raw)
+			continue
+		}
+		bag, err := trustedCertBag(entry)
+		if err != nil {
+			return nil, err
+		}
+		bags = append(bags, bag)
+	}
+
+	safeContents, err := marshalSequence(bags)
+	if err != nil {
+		return nil, err
+	}
+	plain, err := dataContentInfo(safeContents)
+	if err != nil {
+		return nil, err
+	}
+	authSafe, err := marshalSequence(append(append([][]byte{}, k.p12Sealed...), plain))
+	if err != nil {
+		return nil, err
+	}
+
+	pfx := p12PFX{Version: 3}
+	pfx.AuthSafe, err = dataContentInfoValue(authSafe)
+	if err != nil {
+		return nil, err
+	}
+	if k.p12Mac != nil {
+		salt := make([]byte, 20)
+		if _, err := rand.Read(salt); err != nil {
+			return nil, err
+		}
+		mac, err := k.p12Mac.compute(authSafe, salt, password)
+		if err != nil {
+			return nil, err
+		}
+		pfx.MacData = p12MacData{
+			Mac:        p12DigestInfo{Algorithm: k.p12Mac.digest, Digest: mac},
+			MacSalt:    salt,
+			Iterations: k.p12Mac.iterations,
+		}
+	}
+	return asn1.Marshal(pfx)
+}
+
+// trustedCertBag encodes a certificate bag the JDK loads as a trusted certificate
+func trustedCertBag(entry Entry) ([]byte, error) {
+	certBag, err := asn1.Marshal(p12CertBag{ID: oidX509Cert, Data: entry.Certificate.Raw})
+	if err != nil {
+		return nil, err
+	}
+	name, err := asn1.Marshal(asn1.RawValue{Tag: asn1.TagBMPString, Bytes: encodeBMPString(entry.Alias)})
+	if err != nil {
+		return nil, err
+	}
+	usage, err := asn1.Marshal(oidAnyExtendedKeyUsage)
+	if err != nil {
+		return nil, err
+	}
+
+	set := func(content []byte) asn1.RawValue {
+		return asn1.RawValue{Tag: asn1.TagSet, IsCompound: true, Bytes: content}
+	}
+	return asn1.Marshal(p12SafeBag{
+		ID:    oidCertBag,
+		Value: asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: certBag},
+		Attributes: []p12Attribute{
+			{ID: oidFriendlyName, Value: set(name)},
+			{ID: oidJavaTrustedKeyUsage, Value: set(usage)},
+		},
+	})
+}
+
+// compute returns the MAC of message keyed with password
+func (p *p12MacParams) compute(message []byte, salt []byte, password string) ([]byte, error) {
+	newHash, ok := p12MacHashes[p.digest.Algorithm.String()]
+	if !ok {
+		return nil, fmt.Errorf("unsupported PKCS12 MAC algorithm %s", p.digest.Algorithm)
+	}
+	iterations := p.iterations
+	if iterations < 1 {
+		iterations = 1
+	}
+
+	key := p12DeriveKey(newHash, salt, append(encodeBMPString(password), 0, 0), iterations)
+	mac := hmac.New(newHash, key)
+	mac.Write(message)
+	return mac.Sum(nil), nil
+}
+
+// p12DeriveKey derives a MAC key with the PKCS#12 key derivation function
+// (RFC 7292, appendix B.2). MAC keys are as long as one digest, so only the
+// first block of the output is computed.
+func p12DeriveKey(newHash func() hash.Hash, salt []byte, password []byte, iterations int) []byte {
+	const macKeyID = 3
+
+	h := newHash()
+	v := h.BlockSize()
+	fill := func(pattern []byte) []byte {
+		if len(pattern) == 0 {
+			return nil
+		}
+		n := v * ((len(pattern) + v - 1) / v)
+		return bytes.Repeat(pattern, (n+len(pattern)-1)/len(pattern))[:n]
+	}
+
+	h.Write(bytes.Repeat([]byte{macKeyID}, v))
+	h.Write(fill(salt))
+	h.Write(fill(password))
+	key := h.Sum(nil)
+	for i := 1; i < iterations; i++ {
+		h.Reset()
+		h.Write(key)
+		key = h.Sum(key[:0])
+	}
+	return key
+}
+
+// dataContent returns the octets of a data content info
+func dataContent(ci p12ContentInfo) ([]byte, error) {
+	var octets []byte
+	if err := unmarshalDER(ci.Content.Bytes, &octets); err != nil {
+		return nil, err
+	}
+	return octets, nil
+}
+
+// dataContentInfoValue wraps content in a data content info
+func dataContentInfoValue(content []byte) (p12ContentInfo, error) {
+	octets, err := asn1.Marshal(content)
+	if err != nil {
+		return p12ContentInfo{}, err
+	}
+	return p12ContentInfo{
+		ContentType: oidData,
+		Content:     asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: octets},
+	}, nil
+}
+
+// dataContentInfo encodes content as a data content info
+func dataContentInfo(content []byte) ([]byte, error) {
+	ci, err := dataContentInfoValue(content)
+	if err != nil {
+		return nil, err
+	}
+	return asn1.Marshal(ci)
+}
+
+// marshalSequence encodes already encoded elements as a SEQUENCE
+func marshalSequence(elements [][]byte) ([]byte, error) {
+	return asn1.Marshal(asn1.RawValue{Tag: asn1.TagSequence, IsCompound: true, Bytes: bytes.Join(elements, nil)})
+}
+
+// unmarshalDER is asn1.Unmarshal that rejects trailing data
+func unmarshalDER(data []byte, out interface{}) error {
+	rest, err := asn1.Unmarshal(data, out)
+	if err != nil {
+		return err
+	}
+	if len(rest) != 0 {
+		return errors.New("trailing data")
+	}
+	return nil
+}
+
+// encodeBMPString encodes s as UTF-16BE, without a terminator
+func encodeBMPString(s string) []byte {
+	var out []byte
+	for _, unit := range utf16.Encode([]rune(s)) {
+		out = append(out, byte(unit>>8), byte(unit))
+	}
+	return out
+}
+
+// decodeBMPString decodes UTF-16BE, dropping a trailing NUL
+func decodeBMPString(b []byte) string {
+	units := make([]uint16, 0, len(b)/2)
+	for i := 0; i+1 < len(b); i += 2 {
+		units = append(units, uint16(b[i])<<8|uint16(b[i+1]))
+	}
+	if n := len(units); n > 0 && units[n-1] == 0 {
+		units = units[:n-1]
+	}
+	return string(utf16.Decode(units))
+}
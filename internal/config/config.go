@@ -5,14 +5,50 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 )
 
+// DefaultInstallLayout places each build in its own directory so that vendors
+// and patch releases of the same major version can be installed side by side
+const DefaultInstallLayout = `{vendor}\{version}-{arch}`
+
 // Config holds the application configuration
 type Config struct {
-	CustomPaths   []string       `json:"custom_paths"`   // Specific Java installation paths
-	SearchPaths   []string       `json:"search_paths"`   // Base directories to scan for Java installations
-	InstalledJDKs []InstalledJDK `json:"installed_jdks"` // JDKs installed via jv install
-	configPath    string
+	CustomPaths   []string       `json:"custom_paths"`        // Specific Java installation paths
+	SearchPaths   []string       `json:"search_paths"`        // Base directories to scan for Java installations
+	InstalledJDKs []InstalledJDK `json:"installed_jdks"`      // JDKs installed via jv install
+	CacheDir      string         `json:"cache_dir,omitempty"` // Override for the download cache directory
+
+	// SignatureKeys maps a distributor name to armored OpenPGP public key files
+	// that replace the signing keys pinned in jv for that distributor
+	SignatureKeys    map[string][]string `json:"signature_keys,omitempty"`
+	RequireSignature bool                `json:"require_signature,omitempty"` // Refuse archives without a valid signature
+
+	// InstallLayout is the directory template for new installs, relative to the
+	// install root (see DefaultInstallLayout)
+	InstallLayout string `json:"install_layout,omitempty"`
+
+	// CatalogTTL is how long distributor catalogs are served from disk before
+	// being fetched again, as a Go duration such as "24h" (default 24h)
+	CatalogTTL string `json:"catalog_ttl,omitempty"`
+
+	// CABundle is a PEM file whose certificates are added to the truststore
+	// (lib/security/cacerts) of every JDK jv installs
+	CABundle           string `json:"ca_bundle,omitempty"`
+	TruststorePassword string `json:"truststore_password,omitempty"` // Password of JDK truststores (default "changeit")
+
+	// Hooks maps an event ("pre_install", "post_install", "pre_switch" or
+	// "post_switch") to the commands run for it
+	Hooks map[string][]Hook `json:"hooks,omitempty"`
+
+	// AuditFeed is the URL or file of the vulnerability feed jv audit checks against
+	AuditFeed string `json:"audit_feed,omitempty"`
+
+	// PolicyFile is the organization policy restricting which JDKs may be
+	// installed and used; the JV_POLICY environment variable takes precedence
+	PolicyFile string `json:"policy_file,omitempty"`
+
+	configPath string
 }
 
 // InstalledJDK represents a JDK installed through jv install command
@@ -21,7 +57,23 @@ type InstalledJDK struct {
 	Path        string `json:"path"`
 	Distributor string `json:"distributor"`
 	InstalledAt string `json:"installed_at"`
-	Scope       string `json:"scope"` // "system" or "user"
+	Scope       string `json:"scope"`                // "system" or "user"
+	ImageType   string `json:"image_type,omitempty"` // "jdk" (default), "jre", "debugimage", "staticlibs" or "sources"
+	Arch        string `json:"arch,omitempty"`       // Vendor architecture name, e.g. "x64" or "aarch64"
+
+	// Where the install came from; empty for entries recorded by older versions of jv
+	FullVersion string `json:"full_version,omitempty"` // e.g. "21.0.5+11"
+	VendorBuild string `json:"vendor_build,omitempty"` // IMPLEMENTOR_VERSION of the release file, e.g. "Temurin-21.0.5+11"
+	SourceURL   string `json:"source_url,omitempty"`   // Download URL, or the path of a local archive
+	Checksum    string `json:"checksum,omitempty"`     // Digest of the archive, e.g. "sha256:<hex>"
+	Signature   string `json:"signature,omitempty"`    // "verified", "unsigned" or "no-key"
+}
+
+// Hook is a command run before or after jv installs or switches to a JDK
+type Hook struct {
+	Command string `json:"command"`           // Run by the shell (cmd.exe on Windows)
+	Fatal   bool   `json:"fatal,omitempty"`   // A failure aborts the operation instead of only being reported
+	Timeout string `json:"timeout,omitempty"` // Go duration such as "30s" (default 5m)
 }
 
 // Load loads the configuration from the user's home directory
@@ -219,6 +271,62 @@ func (c *Config) GetInstalledJDK(path string) *InstalledJDK {
 	return nil
 }
 
+// GetCacheDir returns the directory used to cache downloaded archives.
+// Uses cache_dir from the config if set, otherwise follows the XDG Base Directory specification.
+func (c *Config) GetCacheDir() string {
+	if c.CacheDir != "" {
+		return filepath.Clean(c.CacheDir)
+	}
+
+	cacheHome := os.Getenv("XDG_CACHE_HOME")
+	if cacheHome != "" {
+		return filepath.Join(cacheHome, "jv")
+	}
+
+	// Fallback to $HOME/.cache/jv (XDG default)
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		homeDir = "."
+	}
+
+	return filepath.Join(homeDir, ".cache", "jv")
+}
+
+// GetCatalogDir returns the directory distributor catalogs and lifecycle data are kept in
+func (c *Config) GetCatalogDir() string {
+	return filepath.Join(c.GetCacheDir(), "catalog")
+}
+
+// GetManifestDir returns the directory the file manifests of installed JDKs are
+// kept in. It sits next to jv.json, so cleaning the cache does not remove them.
+func (c *Config) GetManifestDir() string {
+	return filepath.Join(filepath.Dir(getConfigPath()), "manifests")
+}
+
+// GetInstallLayout returns the directory template used for new installs
+func (c *Config) GetInstallLayout() string {
+	if strings.TrimSpace(c.InstallLayout) != "" {
+		return c.InstallLayout
+	}
+	return DefaultInstallLayout
+}
+
+// GetCatalogTTL returns how long cached distributor catalogs stay fresh
+func (c *Config) GetCatalogTTL() time.Duration {
+	if ttl, err := time.ParseDuration(strings.TrimSpace(c.CatalogTTL)); err == nil && ttl >= 0 {
+		return ttl
+	}
+	return 24 * time.Hour
+}
+
+// GetTruststorePassword returns the password JDK truststores are opened with
+func (c *Config) GetTruststorePassword() string {
+	if c.TruststorePassword != "" {
+		return c.TruststorePassword
+	}
+	return "changeit"
+}
+
 // getConfigPath returns the path to the configuration file
 // Following XDG Base Directory specification
 func getConfigPath() string {
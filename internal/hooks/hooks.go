@@ -0,0 +1,155 @@
+// Package hooks runs the site-specific commands configured in jv.json before
+// and after a JDK is installed or JAVA_HOME is switched.
+package hooks
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"jv/internal/config"
+	"jv/internal/java"
+)
+
+// Event is the moment a hook runs at
+type Event string
+
+const (
+	PreInstall  Event = "pre_install"  // The JDK is verified and about to be moved into place
+	PostInstall Event = "post_install" // The JDK is installed and recorded in the config
+	PreSwitch   Event = "pre_switch"   // JAVA_HOME is about to change
+	PostSwitch  Event = "post_switch"  // JAVA_HOME has changed
+)
+
+// Events lists every event in the order they happen
+var Events = []Event{PreInstall, PostInstall, PreSwitch, PostSwitch}
+
+// defaultTimeout bounds hooks that do not set their own timeout
+const defaultTimeout = 5 * time.Minute
+
+// Target is the JDK a hook runs for, passed to it as environment variables
+type Target struct {
+	Path         string // JV_JAVA_HOME
+	Version      string // JV_JAVA_VERSION
+	Vendor       string // JV_JAVA_VENDOR, "" if unknown
+	Arch         string // JV_JAVA_ARCH, "" if unknown
+	PreviousPath string // JV_PREVIOUS_JAVA_HOME for switch events, "" if JAVA_HOME was not set
+}
+
+// TargetFor describes the JDK at path, reading its vendor and architecture
+// from the release file
+func TargetFor(path string, version string) Target {
+	target := Target{Path: path, Version: version, Arch: java.NewDetector().GetArch(path)}
+	if release, err := java.ReadReleaseFile(path); err == nil {
+		target.Vendor = release["IMPLEMENTOR"]
+	}
+	return target
+}
+
+// Runner runs the hooks of a configuration. A nil Runner runs nothing.
+type Runner struct {
+	hooks map[string][]config.Hook
+}
+
+// NewRunner returns a Runner for the hooks in cfg
+func NewRunner(cfg *config.Config) *Runner {
+	return &Runner{hooks: cfg.Hooks}
+}
+
+// Validate reports events in the configuration that jv does not know and
+// hooks without a command or with an invalid timeout
+func Validate(cfg *config.Config) []string {
+	names := make([]string, 0, len(cfg.Hooks))
+	for name := range cfg.Hooks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var problems []string
+	for _, name := range names {
+		known := false
+		for _, event := range Events {
+			known = known || string(event) == name
+		}
+		if !known {
+			problems = append(problems, fmt.Sprintf("unknown hook event %q", name))
+			continue
+		}
+		for _, hook := range cfg.Hooks[name] {
+			if strings.TrimSpace(hook.Command) == "" {
+				problems = append(problems, fmt.Sprintf("%s hook without a command", name))
+			}
+			if hook.Timeout != "" {
+				if _, err := time.ParseDuration(hook.Timeout); err != nil {
+					problems = append(problems, fmt.Sprintf("%s hook %q: invalid timeout %q", name, hook.Command, hook.Timeout))
+				}
+			}
+		}
+	}
+	return problems
+}
+
+// Run runs the hooks for event one after another. Failures are reported;
+// a failing fatal hook stops the remaining hooks and its error is returned.
+func (r *Runner) Run(ctx context.Context, event Event, target Target) error {
+	if r == nil {
+		return nil
+	}
+
+	for _, hook := range r.hooks[string(event)] {
+		if strings.TrimSpace(hook.Command) == "" {
+			continue
+		}
+
+		fmt.Printf("→ Running %s hook: %s\n", event, hook.Command)
+		err := run(ctx, hook, event, target)
+		if err == nil {
+			continue
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if hook.Fatal {
+			return fmt.Errorf("%s hook %q failed: %w", event, hook.Command, err)
+		}
+		fmt.Printf("⚠ %s hook %q failed: %v\n", event, hook.Command, err)
+	}
+	return nil
+}
+
+// run runs one hook with its output going to jv's own
+func run(ctx context.Context, hook config.Hook, event Event, target Target) error {
+	timeout := defaultTimeout
+	if hook.Timeout != "" {
+		parsed, err := time.ParseDuration(hook.Timeout)
+		if err != nil {
+			return fmt.Errorf("invalid timeout %q: %w", hook.Timeout, err)
+		}
+		timeout = parsed
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	cmd := shellCommand(ctx, hook.Command)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Env = append(os.Environ(),
+		"JV_HOOK="+string(event),
+		"JV_JAVA_HOME="+target.Path,
+		"JV_JAVA_VERSION="+target.Version,
+		"JV_JAVA_VENDOR="+target.Vendor,
+		"JV_JAVA_ARCH="+target.Arch,
+		"JV_PREVIOUS_JAVA_HOME="+target.PreviousPath,
+	)
+
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return fmt.Errorf("timed out after %s", timeout)
+		}
+		return err
+	}
+	return nil
+}
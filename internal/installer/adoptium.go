@@ -1,11 +1,16 @@
 package installer
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	neturl "net/url"
 	"sort"
+	"strings"
+
+	"jv/internal/java"
 )
 
 const adoptiumAPIBase = "https://api.adoptium.net/v3"
@@ -31,27 +36,44 @@ type adoptiumReleasesResponse struct {
 	MostRecentFeatureRelease int   `json:"most_recent_feature_release"`
 }
 
+// adoptiumPackage represents a downloadable archive in API responses
+type adoptiumPackage struct {
+	Link          string `json:"link"`
+	Checksum      string `json:"checksum"`
+	Size          int64  `json:"size"`
+	Name          string `json:"name"`
+	SignatureLink string `json:"signature_link"`
+}
+
+// adoptiumVersionData represents the version of a release in API responses
+type adoptiumVersionData struct {
+	OpenJDKVersion string `json:"openjdk_version"`
+	Major          int    `json:"major"`
+}
+
 // adoptiumAssetResponse represents the API response for asset details
 type adoptiumAssetResponse struct {
 	Binary struct {
-		Package struct {
-			Link     string `json:"link"`
-			Checksum string `json:"checksum"`
-			Size     int64  `json:"size"`
-			Name     string `json:"name"`
-		} `json:"package"`
+		Package adoptiumPackage `json:"package"`
 	} `json:"binary"`
-	Version struct {
-		OpenJDKVersion string `json:"openjdk_version"`
-		Major          int    `json:"major"`
-	} `json:"version"`
+	Version adoptiumVersionData `json:"version"`
+}
+
+// adoptiumRelease represents a release in the feature_releases and release_name endpoints
+type adoptiumRelease struct {
+	ReleaseName string `json:"release_name"`
+	Timestamp   string `json:"timestamp"`
+	Binaries    []struct {
+		Package adoptiumPackage `json:"package"`
+	} `json:"binaries"`
+	VersionData adoptiumVersionData `json:"version_data"`
 }
 
 // GetAvailableVersions fetches available Java versions from Adoptium API
-func (a *AdoptiumDistributor) GetAvailableVersions() ([]JavaRelease, error) {
+func (a *AdoptiumDistributor) GetAvailableVersions(ctx context.Context) ([]JavaRelease, error) {
 	url := fmt.Sprintf("%s/info/available_releases", adoptiumAPIBase)
 
-	resp, err := http.Get(url)
+	resp, err := adoptiumGet(ctx, url)
 	if err != nil {
 		return a.getFallbackVersions(), fmt.Errorf("API request failed, using fallback versions: %w", err)
 	}
@@ -112,17 +134,14 @@ func (a *AdoptiumDistributor) getFallbackVersions() []JavaRelease {
 }
 
 // GetDownloadURL fetches download information for a specific version and architecture
-func (a *AdoptiumDistributor) GetDownloadURL(version string, arch string) (*DownloadInfo, error) {
+func (a *AdoptiumDistributor) GetDownloadURL(ctx context.Context, version string, arch string, imageType ImageType) (*DownloadInfo, error) {
 	// Map Go arch to Adoptium arch
-	adoptiumArch := arch
-	if arch == "amd64" {
-		adoptiumArch = "x64"
-	}
+	adoptiumArch := toAdoptiumArch(arch)
 
-	url := fmt.Sprintf("%s/assets/latest/%s/hotspot?architecture=%s&image_type=jdk&os=windows&vendor=eclipse",
-		adoptiumAPIBase, version, adoptiumArch)
+	url := fmt.Sprintf("%s/assets/latest/%s/hotspot?architecture=%s&image_type=%s&os=windows&vendor=eclipse",
+		adoptiumAPIBase, version, adoptiumArch, imageType)
 
-	resp, err := http.Get(url)
+	resp, err := adoptiumGet(ctx, url)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query download URL: %w", err)
 	}
@@ -143,15 +162,132 @@ func (a *AdoptiumDistributor) GetDownloadURL(version string, arch string) (*Down
 	}
 
 	if len(assets) == 0 {
-		return nil, fmt.Errorf("no JDK found for Java %s on %s", version, arch)
+		return nil, fmt.Errorf("no %s found for Java %s on %s", imageType, version, arch)
 	}
 
 	asset := assets[0]
+	return adoptiumDownloadInfo(asset.Binary.Package, asset.Version, adoptiumArch, imageType), nil
+}
+
+// GetBuilds lists the GA builds of a major version, newest first
+func (a *AdoptiumDistributor) GetBuilds(ctx context.Context, major string, arch string, imageType ImageType) ([]JavaBuild, error) {
+	url := fmt.Sprintf("%s/assets/feature_releases/%s/ga?architecture=%s&image_type=%s&os=windows&vendor=eclipse&jvm_impl=hotspot&page_size=50&sort_order=DESC",
+		adoptiumAPIBase, major, toAdoptiumArch(arch), imageType)
+
+	var releases []adoptiumRelease
+	if err := getAdoptiumJSON(ctx, url, &releases); err != nil {
+		return nil, err
+	}
+
+	builds := make([]JavaBuild, 0, len(releases))
+	for _, release := range releases {
+		if len(release.Binaries) == 0 {
+			continue
+		}
+		date := release.Timestamp
+		if len(date) > len("2006-01-02") {
+			date = date[:len("2006-01-02")]
+		}
+		builds = append(builds, JavaBuild{
+			Version:     adoptiumVersion(release.VersionData.OpenJDKVersion),
+			ReleaseDate: date,
+		})
+	}
+
+	return builds, nil
+}
+
+// GetBuildDownloadURL fetches download information for a particular build, e.g. "17.0.8+7"
+func (a *AdoptiumDistributor) GetBuildDownloadURL(ctx context.Context, version string, arch string, imageType ImageType) (*DownloadInfo, error) {
+	adoptiumArch := toAdoptiumArch(arch)
+	url := fmt.Sprintf("%s/assets/release_name/eclipse/%s?architecture=%s&image_type=%s&os=windows&jvm_impl=hotspot",
+		adoptiumAPIBase, neturl.PathEscape(adoptiumReleaseName(version)), adoptiumArch, imageType)
+
+	var release adoptiumRelease
+	if err := getAdoptiumJSON(ctx, url, &release); err != nil {
+		return nil, fmt.Errorf("build %s: %w", version, err)
+	}
+
+	if len(release.Binaries) == 0 {
+		return nil, fmt.Errorf("no %s found for Java %s on %s", imageType, version, arch)
+	}
+
+	return adoptiumDownloadInfo(release.Binaries[0].Package, release.VersionData, adoptiumArch, imageType), nil
+}
+
+// adoptiumReleaseName maps a version to Adoptium's release name:
+// "17.0.8+7" -> "jdk-17.0.8+7", "8u382-b05" -> "jdk8u382-b05"
+func adoptiumReleaseName(version string) string {
+	if strings.Contains(version, "u") {
+		return "jdk" + version
+	}
+	return "jdk-" + version
+}
+
+// adoptiumVersion normalizes an openjdk_version such as "21.0.5+11-LTS" or
+// "1.8.0_382-b05" to the form used in release names
+func adoptiumVersion(openJDKVersion string) string {
+	version := strings.TrimSuffix(openJDKVersion, "-LTS")
+	if rest, ok := strings.CutPrefix(version, "1.8.0_"); ok {
+		return "8u" + rest
+	}
+	return version
+}
+
+// adoptiumDownloadInfo converts an API package to DownloadInfo
+func adoptiumDownloadInfo(pkg adoptiumPackage, version adoptiumVersionData, arch string, imageType ImageType) *DownloadInfo {
 	return &DownloadInfo{
-		URL:          asset.Binary.Package.Link,
-		Checksum:     asset.Binary.Package.Checksum,
+		URL:          pkg.Link,
+		Checksum:     pkg.Checksum,
 		ChecksumAlgo: "SHA256",
-		Size:         asset.Binary.Package.Size,
-		FileName:     asset.Binary.Package.Name,
-	}, nil
+		Size:         pkg.Size,
+		FileName:     pkg.Name,
+		SignatureURL: pkg.SignatureLink,
+		Version:      adoptiumVersion(version.OpenJDKVersion),
+		Arch:         arch,
+		ImageType:    imageType,
+	}
+}
+
+// toAdoptiumArch maps an architecture name such as "amd64" or "arm64" to Adoptium's naming
+func toAdoptiumArch(arch string) string {
+	if normalized, err := java.NormalizeArch(arch); err == nil {
+		return normalized
+	}
+	return arch
+}
+
+// adoptiumGet performs a GET request against the API that is aborted when ctx is cancelled
+func adoptiumGet(ctx context.Context, url string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	return http.DefaultClient.Do(req)
+}
+
+// getAdoptiumJSON performs a GET request against the API and decodes the JSON response into v
+func getAdoptiumJSON(ctx context.Context, url string, v interface{}) error {
+	resp, err := adoptiumGet(ctx, url)
+	if err != nil {
+		return fmt.Errorf("API request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("not found")
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("API returned status %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	return nil
 }
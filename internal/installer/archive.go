@@ -0,0 +1,246 @@
+package installer
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ulikunitz/xz"
+)
+
+// ArchiveFormat identifies how a JDK archive is packed
+type ArchiveFormat string
+
+const (
+	ArchiveZip   ArchiveFormat = "zip"
+	ArchiveTarGz ArchiveFormat = "tar.gz"
+	ArchiveTarXz ArchiveFormat = "tar.xz"
+)
+
+// archiveFormatFromName guesses the format from a file name, returning "" if unknown
+func archiveFormatFromName(fileName string) ArchiveFormat {
+	name := strings.ToLower(fileName)
+	switch {
+	case strings.HasSuffix(name, ".zip"):
+		return ArchiveZip
+	case strings.HasSuffix(name, ".tar.gz"), strings.HasSuffix(name, ".tgz"):
+		return ArchiveTarGz
+	case strings.HasSuffix(name, ".tar.xz"), strings.HasSuffix(name, ".txz"):
+		return ArchiveTarXz
+	default:
+		return ""
+	}
+}
+
+// sniffArchiveFormat recognizes an archive from its leading magic bytes
+func sniffArchiveFormat(header []byte) ArchiveFormat {
+	switch {
+	case bytes.HasPrefix(header, []byte("PK\x03\x04")):
+		return ArchiveZip
+	case bytes.HasPrefix(header, []byte{0x1f, 0x8b}):
+		return ArchiveTarGz
+	case bytes.HasPrefix(header, []byte{0xfd, '7', 'z', 'X', 'Z', 0x00}):
+		return ArchiveTarXz
+	default:
+		return ""
+	}
+}
+
+// DetectArchiveFormat determines the format of archivePath from fileName (usually
+// DownloadInfo.FileName), falling back to the file's content
+func DetectArchiveFormat(archivePath string, fileName string) (ArchiveFormat, error) {
+	if format := archiveFormatFromName(fileName); format != "" {
+		return format, nil
+	}
+
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open archive: %w", err)
+	}
+	defer file.Close()
+
+	header := make([]byte, 6)
+	n, _ := io.ReadFull(file, header)
+	if format := sniffArchiveFormat(header[:n]); format != "" {
+		return format, nil
+	}
+
+	return "", fmt.Errorf("unsupported archive format: %s", filepath.Base(archivePath))
+}
+
+// ExtractArchive extracts a zip, tar.gz or tar.xz archive to destDir and returns
+// the path of the extracted JDK root
+func ExtractArchive(ctx context.Context, archivePath string, fileName string, destDir string) (string, error) {
+	format, err := DetectArchiveFormat(archivePath, fileName)
+	if err != nil {
+		return "", err
+	}
+
+	if format == ArchiveZip {
+		return ExtractZip(ctx, archivePath, destDir)
+	}
+
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open archive: %w", err)
+	}
+	defer file.Close()
+
+	return ExtractTar(ctx, file, format, destDir)
+}
+
+// ExtractTar extracts a compressed tar stream to destDir and returns the path of
+// the extracted JDK root. The stream is read once, so it can come straight from
+// a download.
+func ExtractTar(ctx context.Context, r io.Reader, format ArchiveFormat, destDir string) (string, error) {
+	var stream io.Reader
+	switch format {
+	case ArchiveTarGz:
+		gz, err := gzip.NewReader(r)
+		if err != nil {
+			return "", fmt.Errorf("failed to open gzip stream: %w", err)
+		}
+		defer gz.Close()
+		stream = gz
+	case ArchiveTarXz:
+		xzReader, err := xz.NewReader(r)
+		if err != nil {
+			return "", fmt.Errorf("failed to open xz stream: %w", err)
+		}
+		stream = xzReader
+	default:
+		return "", fmt.Errorf("unsupported tar format: %s", format)
+	}
+
+	x, err := newExtractor(ctx, destDir)
+	if err != nil {
+		return "", err
+	}
+
+	reader := tar.NewReader(stream)
+	for {
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+		header, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to read tar: %w", err)
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			err = x.mkdir(header.Name)
+		case tar.TypeReg:
+			err = x.writeFile(header.Name, header.FileInfo().Mode(), reader)
+		case tar.TypeSymlink:
+			err = x.symlink(header.Name, header.Linkname)
+		case tar.TypeLink:
+			err = x.hardlink(header.Name, header.Linkname)
+		case tar.TypeXGlobalHeader:
+			// PAX metadata for the whole archive, nothing to extract
+		default:
+			err = fmt.Errorf("unsupported entry type in archive: %s", header.Name)
+		}
+
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return x.finish()
+}
+
+// errStreamAbandoned tells a streaming extraction that its input is incomplete
+var errStreamAbandoned = errors.New("download restarted, extracting from the archive instead")
+
+// streamExtraction unpacks a tar archive while it is being downloaded. Bytes are
+// fed through Write in download order; if the download has to resume or restart,
+// the extraction is abandoned and the caller extracts the finished file instead.
+type streamExtraction struct {
+	pw        *io.PipeWriter
+	destDir   string
+	done      chan struct{}
+	root      string
+	err       error
+	started   bool
+	abandoned bool
+}
+
+// newStreamExtraction starts extracting a tar archive of the given format to destDir
+func newStreamExtraction(ctx context.Context, format ArchiveFormat, destDir string) *streamExtraction {
+	pr, pw := io.Pipe()
+	s := &streamExtraction{
+		pw:      pw,
+		destDir: destDir,
+		done:    make(chan struct{}),
+	}
+
+	go func() {
+		defer close(s.done)
+		s.root, s.err = ExtractTar(ctx, pr, format, destDir)
+		// Unblock the writer if extraction stopped early (trailing padding or an error)
+		pr.CloseWithError(io.ErrClosedPipe)
+	}()
+
+	return s
+}
+
+// writer returns the writer for a download attempt starting at offset, or nil
+// when the extraction can no longer follow the download
+func (s *streamExtraction) writer(offset int64) io.Writer {
+	if s.abandoned {
+		return nil
+	}
+	if s.started || offset != 0 {
+		s.abandon()
+		return nil
+	}
+	s.started = true
+	return s
+}
+
+// Write feeds downloaded bytes to the extraction. It never fails, so a broken
+// extraction cannot interrupt the download itself.
+func (s *streamExtraction) Write(p []byte) (int, error) {
+	if !s.abandoned {
+		s.pw.Write(p)
+	}
+	return len(p), nil
+}
+
+// abandon stops the extraction and discards whatever it has written
+func (s *streamExtraction) abandon() {
+	if s.abandoned {
+		return
+	}
+	s.abandoned = true
+	s.pw.CloseWithError(errStreamAbandoned)
+	<-s.done
+	os.RemoveAll(s.destDir)
+}
+
+// result waits for the extraction after a successful download and returns the
+// extracted JDK root, or "" if the archive still has to be extracted from disk
+func (s *streamExtraction) result() string {
+	if s.abandoned {
+		return ""
+	}
+	s.pw.Close()
+	<-s.done
+
+	if s.err != nil {
+		os.RemoveAll(s.destDir)
+		return ""
+	}
+	return s.root
+}
@@ -0,0 +1,98 @@
+package installer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+const (
+	installLockFile    = ".jv.lock"
+	installLockTimeout = 2 * time.Minute
+	installLockStale   = time.Hour // a lock this old was left behind by a crashed run
+	stagingPrefix      = ".jv-staging-"
+	backupSuffix       = ".jv-backup"
+)
+
+// lockInstallRoot takes an exclusive lock on installBase so that concurrent jv
+// processes never modify the same installation root at the same time.
+// The returned function releases the lock. Waiting stops when ctx is cancelled.
+func lockInstallRoot(ctx context.Context, installBase string) (func(), error) {
+	lockPath := filepath.Join(installBase, installLockFile)
+	deadline := time.Now().Add(installLockTimeout)
+	waiting := false
+
+	for {
+		file, err := os.OpenFile(lockPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if err == nil {
+			file.WriteString(strconv.Itoa(os.Getpid()))
+			file.Close()
+			return func() { os.Remove(lockPath) }, nil
+		}
+		if !os.IsExist(err) {
+			return nil, fmt.Errorf("failed to create lock file: %w", err)
+		}
+
+		if info, statErr := os.Stat(lockPath); statErr == nil && time.Since(info.ModTime()) > installLockStale {
+			os.Remove(lockPath)
+			continue
+		}
+
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("another jv process is installing to %s (remove %s if this is not the case)", installBase, lockPath)
+		}
+		if !waiting {
+			fmt.Println("Waiting for another jv process to finish installing...")
+			waiting = true
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(500 * time.Millisecond):
+		}
+	}
+}
+
+// newStagingDir creates a unique directory inside installBase to prepare a JDK in.
+// Staging on the same volume as the final location lets the install finish with a rename.
+func newStagingDir(installBase string) (string, error) {
+	dir, err := os.MkdirTemp(installBase, stagingPrefix)
+	if err != nil {
+		return "", fmt.Errorf("failed to create staging directory: %w", err)
+	}
+	return dir, nil
+}
+
+// swapInstall moves the staged JDK to finalPath. An existing installation is
+// renamed to a backup first and restored if the new one cannot be moved into
+// place, so a failure never leaves the user without a JDK.
+func swapInstall(stagedPath string, finalPath string) error {
+	backupPath := ""
+	if _, err := os.Stat(finalPath); err == nil {
+		backupPath = finalPath + backupSuffix
+		os.RemoveAll(backupPath)
+		if err := os.Rename(finalPath, backupPath); err != nil {
+			return fmt.Errorf("failed to back up existing installation (is it in use?): %w", err)
+		}
+	}
+
+	if err := os.Rename(stagedPath, finalPath); err != nil {
+		if backupPath != "" {
+			if restoreErr := os.Rename(backupPath, finalPath); restoreErr != nil {
+				return fmt.Errorf("failed to move JDK to final location: %w (previous installation kept at %s)", err, backupPath)
+			}
+		}
+		return fmt.Errorf("failed to move JDK to final location: %w", err)
+	}
+
+	if backupPath != "" {
+		if err := os.RemoveAll(backupPath); err != nil {
+			fmt.Printf("Warning: could not remove previous installation at %s: %v\n", backupPath, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,110 @@
+package installer
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// maxParallelDownloads bounds how many archives a batch install fetches at once
+const maxParallelDownloads = 3
+
+// DownloadArchives downloads several archives concurrently into the cache,
+// rendering one progress bar per download. Checksums are verified while
+// streaming and archives that are already cached are skipped.
+// The returned errors are aligned with infos. Cancelling ctx, or pressing
+// ctrl+c in the progress display, aborts all downloads.
+func DownloadArchives(ctx context.Context, labels []string, infos []*DownloadInfo, cache *Cache) []error {
+	errs := make([]error, len(infos))
+	if len(infos) == 0 {
+		return errs
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	if mode := currentProgressMode(); mode != ProgressTTY {
+		return downloadArchivesPlain(ctx, labels, infos, cache, mode == ProgressNone)
+	}
+
+	model := NewMultiProgressModel(labels)
+	model.interrupt = cancel
+	p := tea.NewProgram(model)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if _, err := p.Run(); err != nil {
+			fmt.Printf("Error running progress: %v\n", err)
+		}
+	}()
+
+	// Give the UI a moment to start
+	time.Sleep(100 * time.Millisecond)
+
+	prefetchAll(ctx, infos, cache, errs, func(idx int) batchDownloadUI {
+		return &multiDownloadUI{program: p, index: idx, label: labels[idx]}
+	})
+
+	// Wait for the final frame, but don't hang if the UI already exited
+	select {
+	case <-done:
+	case <-time.After(500 * time.Millisecond):
+		p.Quit()
+		<-done
+	}
+
+	return errs
+}
+
+// downloadArchivesPlain is DownloadArchives without a terminal: every download
+// logs its progress as lines prefixed with its label
+func downloadArchivesPlain(ctx context.Context, labels []string, infos []*DownloadInfo, cache *Cache, quiet bool) []error {
+	errs := make([]error, len(infos))
+	prefetchAll(ctx, infos, cache, errs, func(idx int) batchDownloadUI {
+		return &lineUI{label: labels[idx] + ": ", quiet: quiet}
+	})
+	return errs
+}
+
+// batchDownloadUI reports one download of a batch
+type batchDownloadUI interface {
+	downloadUI
+	// skip marks the download as not needed, e.g. because the archive is already cached
+	skip(note string)
+}
+
+// prefetchAll downloads infos with at most maxParallelDownloads at a time,
+// storing the outcome of each in errs
+func prefetchAll(ctx context.Context, infos []*DownloadInfo, cache *Cache, errs []error, newUI func(idx int) batchDownloadUI) {
+	sem := make(chan struct{}, maxParallelDownloads)
+	var wg sync.WaitGroup
+	for idx, info := range infos {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			errs[idx] = prefetchArchive(ctx, info, cache, newUI(idx))
+		}()
+	}
+	wg.Wait()
+}
+
+// prefetchArchive downloads and verifies an archive into the cache unless it is already cached
+func prefetchArchive(ctx context.Context, info *DownloadInfo, cache *Cache, ui batchDownloadUI) error {
+	if _, ok := cache.Lookup(info.ChecksumAlgo, info.Checksum); ok {
+		ui.skip("cached")
+		return nil
+	}
+	if err := ctx.Err(); err != nil {
+		ui.finish(err)
+		return err
+	}
+
+	_, err := downloadToCache(ctx, info, cache, ui, nil)
+	return err
+}
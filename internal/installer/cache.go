@@ -0,0 +1,304 @@
+package installer
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	cacheMetaFile   = "entry.json"
+	cachePartialDir = "partial"
+)
+
+// Cache stores verified JDK archives keyed by their checksum so that
+// reinstalling the same build does not download it again
+type Cache struct {
+	dir string
+}
+
+// CacheEntry describes an archive stored in the cache
+type CacheEntry struct {
+	Key      string    `json:"key"`
+	FileName string    `json:"file_name"`
+	URL      string    `json:"url"`
+	Size     int64     `json:"size"`
+	AddedAt  time.Time `json:"added_at"`
+	LastUsed time.Time `json:"last_used"`
+	ModTime  time.Time `json:"mod_time"` // Archive modification time when it was verified
+	Path     string    `json:"-"`        // Full path to the cached archive
+}
+
+// NewCache creates a cache rooted at dir
+func NewCache(dir string) *Cache {
+	return &Cache{dir: dir}
+}
+
+// Dir returns the cache root directory
+func (c *Cache) Dir() string {
+	return c.dir
+}
+
+// PartialPath returns where an in-progress download of fileName should be written.
+// Partial downloads live inside the cache so an interrupted download can be resumed later.
+func (c *Cache) PartialPath(fileName string) (string, error) {
+	dir := filepath.Join(c.dir, cachePartialDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	return filepath.Join(dir, filepath.Base(fileName)), nil
+}
+
+// Lookup returns the cached entry for the given checksum, if present
+func (c *Cache) Lookup(algo string, checksum string) (*CacheEntry, bool) {
+	key := cacheKey(algo, checksum)
+	if key == "" {
+		return nil, false
+	}
+
+	entry, err := c.readEntry(key)
+	if err != nil {
+		return nil, false
+	}
+	if _, err := os.Stat(entry.Path); err != nil {
+		return nil, false
+	}
+
+	return entry, true
+}
+
+// Unchanged reports whether the archive still has the size and modification
+// time it had when it was verified and stored
+func (e *CacheEntry) Unchanged() bool {
+	stat, err := os.Stat(e.Path)
+	if err != nil {
+		return false
+	}
+	return stat.Size() == e.Size && stat.ModTime().Equal(e.ModTime)
+}
+
+// Touch records that a cached entry has just been used
+func (c *Cache) Touch(entry *CacheEntry) {
+	entry.LastUsed = time.Now()
+	c.writeEntry(entry)
+}
+
+// Store moves a verified archive into the cache and returns its entry
+func (c *Cache) Store(srcPath string, info *DownloadInfo) (*CacheEntry, error) {
+	key := cacheKey(info.ChecksumAlgo, info.Checksum)
+	if key == "" {
+		return nil, fmt.Errorf("cannot cache %s without a checksum", info.FileName)
+	}
+
+	entryDir := filepath.Join(c.dir, key)
+	if err := os.MkdirAll(entryDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create cache entry: %w", err)
+	}
+
+	destPath := filepath.Join(entryDir, filepath.Base(info.FileName))
+	if err := os.Rename(srcPath, destPath); err != nil {
+		// Rename fails across volumes; fall back to copying
+		if err := copyFile(srcPath, destPath); err != nil {
+			os.RemoveAll(entryDir)
+			return nil, fmt.Errorf("failed to store archive in cache: %w", err)
+		}
+		os.Remove(srcPath)
+	}
+
+	size := info.Size
+	var modTime time.Time
+	if stat, err := os.Stat(destPath); err == nil {
+		size = stat.Size()
+		modTime = stat.ModTime()
+	}
+
+	now := time.Now()
+	entry := &CacheEntry{
+		Key:      key,
+		FileName: filepath.Base(info.FileName),
+		URL:      info.URL,
+		Size:     size,
+		AddedAt:  now,
+		LastUsed: now,
+		ModTime:  modTime,
+		Path:     destPath,
+	}
+	if err := c.writeEntry(entry); err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+// Remove deletes a single entry from the cache
+func (c *Cache) Remove(entry *CacheEntry) error {
+	return os.RemoveAll(filepath.Join(c.dir, entry.Key))
+}
+
+// List returns all cached entries, most recently used first
+func (c *Cache) List() ([]CacheEntry, error) {
+	dirEntries, err := os.ReadDir(c.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read cache directory: %w", err)
+	}
+
+	entries := make([]CacheEntry, 0, len(dirEntries))
+	for _, d := range dirEntries {
+		if !d.IsDir() || !isCacheKey(d.Name()) {
+			continue
+		}
+		entry, err := c.readEntry(d.Name())
+		if err != nil {
+			continue
+		}
+		entries = append(entries, *entry)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].LastUsed.After(entries[j].LastUsed)
+	})
+
+	return entries, nil
+}
+
+// Prune removes entries not used within the given duration and returns them
+func (c *Cache) Prune(olderThan time.Duration) ([]CacheEntry, error) {
+	entries, err := c.List()
+	if err != nil {
+		return nil, err
+	}
+
+	cutoff := time.Now().Add(-olderThan)
+	removed := []CacheEntry{}
+	for i := range entries {
+		if entries[i].LastUsed.After(cutoff) {
+			continue
+		}
+		if err := c.Remove(&entries[i]); err != nil {
+			return removed, fmt.Errorf("failed to remove %s: %w", entries[i].FileName, err)
+		}
+		removed = append(removed, entries[i])
+	}
+
+	return removed, nil
+}
+
+// Clean removes every cached archive and partial download, returning the bytes
+// freed. The catalogs and signing keys kept next to them in the cache directory
+// are left alone, so that installs keep working offline.
+func (c *Cache) Clean() (int64, error) {
+	dirEntries, err := os.ReadDir(c.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("failed to clean cache: %w", err)
+	}
+
+	var freed int64
+	for _, d := range dirEntries {
+		if !d.IsDir() || (d.Name() != cachePartialDir && !isCacheKey(d.Name())) {
+			continue
+		}
+
+		dir := filepath.Join(c.dir, d.Name())
+		var size int64
+		filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+			if err == nil && !info.IsDir() {
+				size += info.Size()
+			}
+			return nil
+		})
+		if err := os.RemoveAll(dir); err != nil {
+			return freed, fmt.Errorf("failed to clean cache: %w", err)
+		}
+		freed += size
+	}
+
+	return freed, nil
+}
+
+func (c *Cache) readEntry(key string) (*CacheEntry, error) {
+	data, err := os.ReadFile(filepath.Join(c.dir, key, cacheMetaFile))
+	if err != nil {
+		return nil, err
+	}
+
+	var entry CacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, err
+	}
+	entry.Key = key
+	entry.Path = filepath.Join(c.dir, key, entry.FileName)
+
+	return &entry, nil
+}
+
+func (c *Cache) writeEntry(entry *CacheEntry) error {
+	data, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(c.dir, entry.Key, cacheMetaFile), data, 0644)
+}
+
+// cacheKey builds the content address for an archive, e.g. "sha256-<hex>".
+// It is "" unless checksum is a digest of the algorithm's length in hex, so a
+// key is always a plain directory name.
+func cacheKey(algo string, checksum string) string {
+	checksum = strings.ToLower(strings.TrimSpace(checksum))
+	if checksum == "" {
+		return ""
+	}
+
+	name, err := normalizeChecksumAlgo(algo, checksum)
+	if err != nil {
+		return ""
+	}
+	hasher, err := newHasher(name, checksum)
+	if err != nil {
+		return ""
+	}
+	if digest, err := hex.DecodeString(checksum); err != nil || len(digest) != hasher.Size() {
+		return ""
+	}
+
+	return name + "-" + checksum
+}
+
+// isCacheKey reports whether name is the directory of a cache entry
+func isCacheKey(name string) bool {
+	algo, checksum, found := strings.Cut(name, "-")
+	return found && cacheKey(algo, checksum) == name
+}
+
+// copyFile copies src to dst, replacing dst if it exists
+func copyFile(src string, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		os.Remove(dst)
+		return err
+	}
+
+	return out.Close()
+}
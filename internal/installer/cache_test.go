@@ -0,0 +1,71 @@
+package installer
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCacheKey(t *testing.T) {
+	sha256Hex := strings.Repeat("ab", 32)
+	tests := []struct {
+		algo     string
+		checksum string
+		want     string
+	}{
+		{"SHA256", sha256Hex, "sha256-" + sha256Hex},
+		{"", strings.ToUpper(sha256Hex), "sha256-" + sha256Hex},
+		{"sha-512", strings.Repeat("cd", 64), "sha512-" + strings.Repeat("cd", 64)},
+		{"sha1", strings.Repeat("ef", 20), "sha1-" + strings.Repeat("ef", 20)},
+		{"sha256", "", ""},
+		{"sha256", strings.Repeat("ab", 20), ""},       // Wrong length for the algorithm
+		{"sha256", strings.Repeat("zz", 32), ""},       // Not hex
+		{"sha256", "../../" + sha256Hex[6:], ""},       // Path traversal
+		{"md5", strings.Repeat("ab", 16), ""},          // Unsupported algorithm
+		{"sha1", strings.Repeat("ab", 19) + "a\\", ""}, // Separator
+	}
+	for _, tt := range tests {
+		if got := cacheKey(tt.algo, tt.checksum); got != tt.want {
+			t.Errorf("cacheKey(%q, %q) = %q, want %q", tt.algo, tt.checksum, got, tt.want)
+		}
+	}
+}
+
+func TestCacheCleanKeepsCatalogAndKeys(t *testing.T) {
+	dir := t.TempDir()
+	write := func(rel string, size int) {
+		path := filepath.Join(dir, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, make([]byte, size), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	key := "sha256-" + strings.Repeat("ab", 32)
+	write(key+"/jdk.zip", 100)
+	write(key+"/"+cacheMetaFile, 10)
+	write(cachePartialDir+"/jdk-17.zip.part", 5)
+	write("catalog/adoptium.json", 1000)
+	write("keys/"+adoptiumKeyFingerprint+".asc", 1000)
+
+	freed, err := NewCache(dir).Clean()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if freed != 115 {
+		t.Errorf("freed %d bytes, want 115", freed)
+	}
+	for _, gone := range []string{key, cachePartialDir} {
+		if _, err := os.Stat(filepath.Join(dir, gone)); !os.IsNotExist(err) {
+			t.Errorf("%s was not removed", gone)
+		}
+	}
+	for _, kept := range []string{"catalog/adoptium.json", "keys/" + adoptiumKeyFingerprint + ".asc"} {
+		if _, err := os.Stat(filepath.Join(dir, filepath.FromSlash(kept))); err != nil {
+			t.Errorf("%s was removed: %v", kept, err)
+		}
+	}
+}
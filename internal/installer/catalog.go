@@ -0,0 +1,272 @@
+package installer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// catalogData is the on-disk form of a distributor's catalog
+type catalogData struct {
+	Distributor string                     `json:"distributor"`
+	Versions    *catalogVersions           `json:"versions,omitempty"`
+	Downloads   map[string]catalogDownload `json:"downloads,omitempty"` // key: version/arch/image
+	Builds      map[string]catalogBuilds   `json:"builds,omitempty"`    // key: major/arch/image
+}
+
+type catalogVersions struct {
+	FetchedAt time.Time     `json:"fetched_at"`
+	Releases  []JavaRelease `json:"releases"`
+}
+
+type catalogDownload struct {
+	FetchedAt time.Time     `json:"fetched_at"`
+	Info      *DownloadInfo `json:"info"`
+}
+
+type catalogBuilds struct {
+	FetchedAt time.Time   `json:"fetched_at"`
+	Builds    []JavaBuild `json:"builds"`
+}
+
+// CachedDistributor wraps a Distributor with an on-disk catalog. Answers younger
+// than the TTL are served from disk; older ones are refreshed, and when the
+// distributor cannot be reached the last known answer is used instead. A
+// cancelled request is never answered from the catalog.
+type CachedDistributor struct {
+	inner Distributor
+	path  string
+	ttl   time.Duration
+
+	mu    sync.Mutex
+	data  *catalogData
+	asOf  time.Time // Oldest stale answer served because the distributor was unreachable
+	stale bool
+}
+
+// NewCachedDistributor creates a catalog for inner stored in dir
+func NewCachedDistributor(inner Distributor, dir string, ttl time.Duration) *CachedDistributor {
+	name := strings.ToLower(strings.ReplaceAll(inner.Name(), " ", "-"))
+	return &CachedDistributor{
+		inner: inner,
+		path:  filepath.Join(dir, name+".json"),
+		ttl:   ttl,
+	}
+}
+
+// Name returns the distributor name
+func (c *CachedDistributor) Name() string {
+	return c.inner.Name()
+}
+
+// Stale reports whether any answer so far came from an outdated catalog because
+// the distributor was unreachable, and how old the oldest such answer was
+func (c *CachedDistributor) Stale() (time.Time, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.asOf, c.stale
+}
+
+// GetAvailableVersions returns the available versions, from the catalog if fresh
+func (c *CachedDistributor) GetAvailableVersions(ctx context.Context) ([]JavaRelease, error) {
+	c.mu.Lock()
+	cached := c.load().Versions
+	c.mu.Unlock()
+
+	if cached != nil && c.fresh(cached.FetchedAt) {
+		return cached.Releases, nil
+	}
+
+	releases, err := c.inner.GetAvailableVersions(ctx)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if cached != nil {
+			c.markStale(cached.FetchedAt)
+			return cached.Releases, nil
+		}
+		// Nothing cached: pass on whatever fallback the distributor offers
+		return releases, err
+	}
+
+	c.update(func(data *catalogData) {
+		data.Versions = &catalogVersions{FetchedAt: time.Now(), Releases: releases}
+	})
+	return releases, nil
+}
+
+// GetDownloadURL returns download information for the latest build of a version
+func (c *CachedDistributor) GetDownloadURL(ctx context.Context, version string, arch string, imageType ImageType) (*DownloadInfo, error) {
+	return c.download(ctx, version, arch, imageType, c.inner.GetDownloadURL)
+}
+
+// GetBuildDownloadURL returns download information for a particular build
+func (c *CachedDistributor) GetBuildDownloadURL(ctx context.Context, version string, arch string, imageType ImageType) (*DownloadInfo, error) {
+	return c.download(ctx, version, arch, imageType, c.inner.GetBuildDownloadURL)
+}
+
+// GetBuilds lists the builds of a major version
+func (c *CachedDistributor) GetBuilds(ctx context.Context, major string, arch string, imageType ImageType) ([]JavaBuild, error) {
+	key := catalogKey(major, arch, imageType)
+
+	c.mu.Lock()
+	cached, ok := c.load().Builds[key]
+	c.mu.Unlock()
+
+	if ok && c.fresh(cached.FetchedAt) {
+		return cached.Builds, nil
+	}
+
+	builds, err := c.inner.GetBuilds(ctx, major, arch, imageType)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if ok {
+			c.markStale(cached.FetchedAt)
+			return cached.Builds, nil
+		}
+		return nil, err
+	}
+
+	c.update(func(data *catalogData) {
+		if data.Builds == nil {
+			data.Builds = make(map[string]catalogBuilds)
+		}
+		data.Builds[key] = catalogBuilds{FetchedAt: time.Now(), Builds: builds}
+	})
+	return builds, nil
+}
+
+// Refresh fetches the list of versions and the latest build of every version
+// for arch and imageType, ignoring the TTL. It returns how many versions were
+// refreshed and the first error for any version that could not be.
+func (c *CachedDistributor) Refresh(ctx context.Context, arch string, imageType ImageType) (int, error) {
+	releases, err := c.inner.GetAvailableVersions(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch versions from %s: %w", c.Name(), err)
+	}
+	c.update(func(data *catalogData) {
+		data.Versions = &catalogVersions{FetchedAt: time.Now(), Releases: releases}
+	})
+
+	refreshed := 0
+	var firstErr error
+	for _, release := range releases {
+		info, err := c.inner.GetDownloadURL(ctx, release.Version, arch, imageType)
+		if ctx.Err() != nil {
+			return refreshed, ctx.Err()
+		}
+		if err != nil {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("Java %s: %w", release.Version, err)
+			}
+			continue
+		}
+		c.storeDownload(catalogKey(release.Version, arch, imageType), info)
+		refreshed++
+	}
+
+	return refreshed, firstErr
+}
+
+// download serves a DownloadInfo from the catalog or fetches it with fetch
+func (c *CachedDistributor) download(ctx context.Context, version string, arch string, imageType ImageType,
+	fetch func(context.Context, string, string, ImageType) (*DownloadInfo, error)) (*DownloadInfo, error) {
+	key := catalogKey(version, arch, imageType)
+
+	c.mu.Lock()
+	cached, ok := c.load().Downloads[key]
+	c.mu.Unlock()
+
+	if ok && c.fresh(cached.FetchedAt) {
+		return cached.Info, nil
+	}
+
+	info, err := fetch(ctx, version, arch, imageType)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if ok {
+			c.markStale(cached.FetchedAt)
+			return cached.Info, nil
+		}
+		return nil, err
+	}
+
+	c.storeDownload(key, info)
+	return info, nil
+}
+
+func (c *CachedDistributor) storeDownload(key string, info *DownloadInfo) {
+	c.update(func(data *catalogData) {
+		if data.Downloads == nil {
+			data.Downloads = make(map[string]catalogDownload)
+		}
+		data.Downloads[key] = catalogDownload{FetchedAt: time.Now(), Info: info}
+	})
+}
+
+func (c *CachedDistributor) fresh(fetchedAt time.Time) bool {
+	return time.Since(fetchedAt) < c.ttl
+}
+
+func (c *CachedDistributor) markStale(fetchedAt time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.stale || fetchedAt.Before(c.asOf) {
+		c.asOf = fetchedAt
+	}
+	c.stale = true
+}
+
+// load reads the catalog from disk once; callers must hold mu
+func (c *CachedDistributor) load() *catalogData {
+	if c.data != nil {
+		return c.data
+	}
+
+	c.data = &catalogData{Distributor: c.inner.Name()}
+	if raw, err := os.ReadFile(c.path); err == nil {
+		var data catalogData
+		if err := json.Unmarshal(raw, &data); err == nil {
+			data.Distributor = c.inner.Name()
+			c.data = &data
+		}
+	}
+	return c.data
+}
+
+// update applies change to the catalog and writes it to disk. A catalog that
+// cannot be written only costs a later refetch, so write errors are ignored.
+func (c *CachedDistributor) update(change func(*catalogData)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	data := c.load()
+	change(data)
+
+	raw, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0755); err != nil {
+		return
+	}
+	tmpPath := c.path + ".tmp"
+	if err := os.WriteFile(tmpPath, raw, 0644); err == nil {
+		os.Rename(tmpPath, c.path)
+	}
+}
+
+// catalogKey identifies a catalog answer, e.g. "21/x64/jdk"
+func catalogKey(version string, arch string, imageType ImageType) string {
+	return version + "/" + toAdoptiumArch(arch) + "/" + string(imageType)
+}
@@ -0,0 +1,140 @@
+package installer
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"strings"
+)
+
+// normalizeChecksumAlgo maps names like "SHA256", "SHA-256" or "sha256" to a canonical form.
+// When algo is empty it is inferred from the length of the hex checksum.
+func normalizeChecksumAlgo(algo string, checksum string) (string, error) {
+	name := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(algo), "-", ""))
+	if name == "" {
+		switch len(strings.TrimSpace(checksum)) {
+		case sha256.Size * 2:
+			name = "sha256"
+		case sha512.Size * 2:
+			name = "sha512"
+		case sha1.Size * 2:
+			name = "sha1"
+		default:
+			return "", fmt.Errorf("cannot determine checksum algorithm")
+		}
+	}
+
+	switch name {
+	case "sha256", "sha512", "sha1":
+		return name, nil
+	default:
+		return "", fmt.Errorf("unsupported checksum algorithm %q", algo)
+	}
+}
+
+// newHasher returns a hash for the algorithm named in DownloadInfo.ChecksumAlgo.
+// SHA-1 is only supported for legacy mirrors that publish nothing stronger.
+func newHasher(algo string, checksum string) (hash.Hash, error) {
+	name, err := normalizeChecksumAlgo(algo, checksum)
+	if err != nil {
+		return nil, err
+	}
+
+	switch name {
+	case "sha512":
+		return sha512.New(), nil
+	case "sha1":
+		return sha1.New(), nil
+	default:
+		return sha256.New(), nil
+	}
+}
+
+// checkDigest compares a computed digest with the expected hex checksum
+func checkDigest(hasher hash.Hash, expectedChecksum string) error {
+	actualChecksum := hex.EncodeToString(hasher.Sum(nil))
+	if !strings.EqualFold(actualChecksum, strings.TrimSpace(expectedChecksum)) {
+		return fmt.Errorf("checksum mismatch: expected %s, got %s", expectedChecksum, actualChecksum)
+	}
+	return nil
+}
+
+// VerifyChecksum verifies the checksum of a file using the given algorithm
+func VerifyChecksum(filePath string, algo string, expectedChecksum string) error {
+	hasher, err := newHasher(algo, expectedChecksum)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := io.Copy(hasher, file); err != nil {
+		return fmt.Errorf("failed to calculate checksum: %w", err)
+	}
+
+	return checkDigest(hasher, expectedChecksum)
+}
+
+// FileChecksum returns the hex digest of a file using the given algorithm
+func FileChecksum(filePath string, algo string) (string, error) {
+	hasher, err := newHasher(algo, "")
+	if err != nil {
+		return "", err
+	}
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := io.Copy(hasher, file); err != nil {
+		return "", fmt.Errorf("failed to calculate checksum: %w", err)
+	}
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
+
+// formatChecksum returns a digest in the "sha256:<hex>" form, "" without a digest
+func formatChecksum(algo string, checksum string) string {
+	if checksum == "" {
+		return ""
+	}
+	name, err := normalizeChecksumAlgo(algo, checksum)
+	if err != nil {
+		name = strings.ToLower(algo)
+	}
+	return name + ":" + strings.ToLower(strings.TrimSpace(checksum))
+}
+
+// ParseChecksumSpec splits a checksum given on the command line, such as
+// "sha256:<hex>" or a bare hex digest, into its algorithm and digest
+func ParseChecksumSpec(spec string) (string, string, error) {
+	algo, digest, found := strings.Cut(strings.TrimSpace(spec), ":")
+	if !found {
+		algo, digest = "", algo
+	}
+
+	digest = strings.ToLower(strings.TrimSpace(digest))
+	if _, err := hex.DecodeString(digest); err != nil || digest == "" {
+		return "", "", fmt.Errorf("invalid checksum %q: expected a hex digest", spec)
+	}
+
+	name, err := normalizeChecksumAlgo(algo, digest)
+	if err != nil {
+		return "", "", err
+	}
+	if hasher, _ := newHasher(name, digest); hasher != nil && len(digest) != hasher.Size()*2 {
+		return "", "", fmt.Errorf("invalid checksum %q: wrong length for %s", spec, name)
+	}
+
+	return name, digest, nil
+}
@@ -0,0 +1,149 @@
+package installer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"jv/internal/java"
+)
+
+// extractionRatio estimates the size of an extracted JDK from its archive size.
+// JDK archives expand to roughly twice their size; the rest is headroom for
+// filesystem overhead and images that compress better.
+const extractionRatio = 2.5
+
+// InstallPlan describes what an install will download and write, and where
+type InstallPlan struct {
+	Version      string // Full version, "" when only known after extraction
+	Distributor  string
+	ImageType    ImageType
+	Arch         string
+	FileName     string
+	ArchiveSize  int64
+	DownloadSize int64 // Bytes still to download: 0 when cached, less when a partial download is resumed
+	InstallSize  int64 // Estimated size of the extracted JDK
+	CacheDir     string
+	InstallPath  string
+	Cached       bool
+	Replaces     bool // An installation already exists at InstallPath and will be replaced
+}
+
+// PlanInstall works out where downloadInfo will be installed and how much space
+// the download and the extracted JDK need. version is the major version.
+func PlanInstall(downloadInfo *DownloadInfo, version string, distributor string, opts InstallOptions) (*InstallPlan, error) {
+	installRoot, err := InstallRoot(opts.SystemWide)
+	if err != nil {
+		return nil, err
+	}
+
+	arch := downloadInfo.Arch
+	if arch == "" {
+		arch = java.HostArch()
+	}
+	finalPath, err := InstallDir(installRoot, opts.Layout, distributor, downloadInfo.Version, version, arch, downloadInfo.ImageType)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &InstallPlan{
+		Version:      downloadInfo.Version,
+		Distributor:  distributor,
+		ImageType:    downloadInfo.ImageType,
+		Arch:         arch,
+		FileName:     downloadInfo.FileName,
+		ArchiveSize:  downloadInfo.Size,
+		DownloadSize: downloadInfo.Size,
+		InstallSize:  int64(float64(downloadInfo.Size) * extractionRatio),
+		CacheDir:     opts.Cache.Dir(),
+		InstallPath:  finalPath,
+		Replaces:     isDir(finalPath),
+	}
+
+	if _, ok := opts.Cache.Lookup(downloadInfo.ChecksumAlgo, downloadInfo.Checksum); ok {
+		plan.Cached = true
+		plan.DownloadSize = 0
+	} else if info, err := os.Stat(filepath.Join(opts.Cache.Dir(), cachePartialDir, filepath.Base(downloadInfo.FileName))); err == nil && info.Size() < plan.DownloadSize {
+		plan.DownloadSize -= info.Size()
+	}
+
+	return plan, nil
+}
+
+// VolumeUsage is the space a set of installs needs on one volume
+type VolumeUsage struct {
+	Volume string
+	Needed int64
+	Free   int64
+	Known  bool // Free could be determined
+}
+
+// Enough reports whether the volume has room, assuming so when its free space is unknown
+func (u VolumeUsage) Enough() bool {
+	return !u.Known || u.Free >= u.Needed
+}
+
+// PlanDiskUsage adds up what plans need on each volume: downloads go to the
+// cache, extraction and staging to the install location
+func PlanDiskUsage(plans []*InstallPlan) []VolumeUsage {
+	usage := make(map[string]*VolumeUsage)
+	var order []string
+
+	add := func(path string, size int64) {
+		if size <= 0 {
+			return
+		}
+		dir := existingParent(path)
+		volume := volumeOf(dir)
+		u, ok := usage[volume]
+		if !ok {
+			u = &VolumeUsage{Volume: volume}
+			if free, err := freeDiskSpace(dir); err == nil {
+				u.Free = free
+				u.Known = true
+			}
+			usage[volume] = u
+			order = append(order, volume)
+		}
+		u.Needed += size
+	}
+
+	for _, plan := range plans {
+		add(plan.CacheDir, plan.DownloadSize)
+		add(plan.InstallPath, plan.InstallSize)
+	}
+
+	sort.Strings(order)
+	result := make([]VolumeUsage, 0, len(order))
+	for _, volume := range order {
+		result = append(result, *usage[volume])
+	}
+	return result
+}
+
+// CheckDiskSpace fails when a volume does not have room for all plans together
+func CheckDiskSpace(plans []*InstallPlan) error {
+	for _, u := range PlanDiskUsage(plans) {
+		if !u.Enough() {
+			return fmt.Errorf("not enough disk space on %s: about %s needed, %s free", u.Volume, FormatSize(u.Needed), FormatSize(u.Free))
+		}
+	}
+	return nil
+}
+
+// existingParent returns path or its closest ancestor that exists, since the
+// install directory usually does not exist yet
+func existingParent(path string) string {
+	path = filepath.Clean(path)
+	for {
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+		parent := filepath.Dir(path)
+		if parent == path {
+			return path
+		}
+		path = parent
+	}
+}
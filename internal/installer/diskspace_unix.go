@@ -0,0 +1,48 @@
+//go:build unix
+
+package installer
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+// freeDiskSpace returns the bytes available to the current user on the volume holding dir
+func freeDiskSpace(dir string) (int64, error) {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(dir, &stat); err != nil {
+		return 0, err
+	}
+	return int64(stat.Bavail) * int64(stat.Bsize), nil
+}
+
+// volumeOf returns the mount point of the volume holding dir
+func volumeOf(dir string) string {
+	dev := func(path string) (uint64, bool) {
+		info, err := os.Stat(path)
+		if err != nil {
+			return 0, false
+		}
+		stat, ok := info.Sys().(*syscall.Stat_t)
+		if !ok {
+			return 0, false
+		}
+		return uint64(stat.Dev), true
+	}
+
+	id, ok := dev(dir)
+	if !ok {
+		return dir
+	}
+	for {
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return dir
+		}
+		if parentID, ok := dev(parent); !ok || parentID != id {
+			return dir
+		}
+		dir = parent
+	}
+}
@@ -0,0 +1,35 @@
+package installer
+
+import (
+	"path/filepath"
+
+	"golang.org/x/sys/windows"
+)
+
+// freeDiskSpace returns the bytes available to the current user on the volume holding dir
+func freeDiskSpace(dir string) (int64, error) {
+	path, err := windows.UTF16PtrFromString(dir)
+	if err != nil {
+		return 0, err
+	}
+
+	var available, total, free uint64
+	if err := windows.GetDiskFreeSpaceEx(path, &available, &total, &free); err != nil {
+		return 0, err
+	}
+	return int64(available), nil
+}
+
+// volumeOf returns the mount point of the volume holding dir, e.g. `C:\`
+func volumeOf(dir string) string {
+	path, err := windows.UTF16PtrFromString(dir)
+	if err != nil {
+		return filepath.VolumeName(dir)
+	}
+
+	buf := make([]uint16, windows.MAX_PATH+1)
+	if err := windows.GetVolumePathName(path, &buf[0], uint32(len(buf))); err != nil {
+		return filepath.VolumeName(dir)
+	}
+	return windows.UTF16ToString(buf)
+}
@@ -1,10 +1,55 @@
 package installer
 
-// Distributor represents a Java distribution provider
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Distributor represents a Java distribution provider.
+// Requests are aborted when ctx is cancelled.
 type Distributor interface {
 	Name() string
-	GetAvailableVersions() ([]JavaRelease, error)
-	GetDownloadURL(version string, arch string) (*DownloadInfo, error)
+	GetAvailableVersions(ctx context.Context) ([]JavaRelease, error)
+	GetDownloadURL(ctx context.Context, version string, arch string, imageType ImageType) (*DownloadInfo, error)
+	// GetBuilds lists the builds published for a major version, newest first
+	GetBuilds(ctx context.Context, major string, arch string, imageType ImageType) ([]JavaBuild, error)
+	// GetBuildDownloadURL fetches download information for a full version such as "17.0.8+7"
+	GetBuildDownloadURL(ctx context.Context, version string, arch string, imageType ImageType) (*DownloadInfo, error)
+}
+
+// ImageType selects what kind of image of a build is installed
+type ImageType string
+
+const (
+	ImageJDK        ImageType = "jdk"        // Full development kit
+	ImageJRE        ImageType = "jre"        // Runtime only, no javac
+	ImageDebug      ImageType = "debugimage" // Debug symbols for the JDK
+	ImageStaticLibs ImageType = "staticlibs" // Static libraries for native image builders
+	ImageSources    ImageType = "sources"    // Source archive of the build
+)
+
+// ImageTypes lists the supported image types in menu order
+var ImageTypes = []ImageType{ImageJDK, ImageJRE, ImageDebug, ImageStaticLibs, ImageSources}
+
+// ParseImageType validates an image type name, defaulting to jdk when empty
+func ParseImageType(name string) (ImageType, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		return ImageJDK, nil
+	}
+	for _, t := range ImageTypes {
+		if string(t) == name {
+			return t, nil
+		}
+	}
+	return "", fmt.Errorf("unknown image type %q (expected jdk, jre, debugimage, staticlibs or sources)", name)
+}
+
+// IsRuntime reports whether the image contains a java launcher and can be used as JAVA_HOME
+func (t ImageType) IsRuntime() bool {
+	return t == ImageJDK || t == ImageJRE || t == ""
 }
 
 // JavaRelease represents an available Java version
@@ -14,11 +59,72 @@ type JavaRelease struct {
 	OpenJDKVersion string
 }
 
+// JavaBuild is a single patch/build of a major version, e.g. 17.0.8+7
+type JavaBuild struct {
+	Version     string // Full version, e.g. "17.0.8+7" or "8u382-b05"
+	ReleaseDate string // YYYY-MM-DD, empty if unknown
+}
+
+// fullVersionPattern matches version specs naming a particular release rather
+// than a major: at least major.minor.patch, a GA build or a Java 8 update.
+// Partial versions such as "17.0" or "1.8" name no release and do not match.
+var fullVersionPattern = regexp.MustCompile(`^\d+\.\d+\.\d+(\.\d+)*(\+\d+)?$|^\d+\+\d+$|^\d+u\d+(-b\d+)?$`)
+
+// buildNumberPattern matches the build number of a full version ("+7", "-b05")
+var buildNumberPattern = regexp.MustCompile(`\+\d+$|-b\d+$`)
+
+// IsFullVersion reports whether spec names a particular release ("17.0.8+7",
+// "17.0.8", "11.0.20.1+1", "21+35", "8u382-b05") rather than a major version ("17")
+func IsFullVersion(spec string) bool {
+	return fullVersionPattern.MatchString(strings.TrimSpace(spec))
+}
+
+// HasBuildNumber reports whether a full version names its build ("17.0.8+7",
+// "8u382-b05") rather than only the release ("17.0.8", "8u382")
+func HasBuildNumber(spec string) bool {
+	return buildNumberPattern.MatchString(strings.TrimSpace(spec))
+}
+
+// resolveBuild completes a full version without a build number with the
+// newest build the distributor lists for it, e.g. "17.0.8" -> "17.0.8+7";
+// distributors only publish releases under their full build name
+func resolveBuild(ctx context.Context, distributor Distributor, spec string, arch string, imageType ImageType) (string, error) {
+	spec = strings.TrimSpace(spec)
+	if HasBuildNumber(spec) {
+		return spec, nil
+	}
+
+	builds, err := distributor.GetBuilds(ctx, MajorVersion(spec), arch, imageType)
+	if err != nil {
+		return "", err
+	}
+	for _, build := range builds {
+		if build.Version == spec || strings.HasPrefix(build.Version, spec+"+") || strings.HasPrefix(build.Version, spec+"-b") {
+			return build.Version, nil
+		}
+	}
+	return "", fmt.Errorf("no build of Java %s found; give its build number, e.g. 17.0.8+7 or 8u382-b05", spec)
+}
+
+// MajorVersion returns the major version of a version spec, e.g. "17" for "17.0.8+7"
+func MajorVersion(spec string) string {
+	spec = strings.TrimSpace(spec)
+	end := strings.IndexFunc(spec, func(r rune) bool { return r < '0' || r > '9' })
+	if end < 0 {
+		return spec
+	}
+	return spec[:end]
+}
+
 // DownloadInfo contains information needed to download a JDK
 type DownloadInfo struct {
 	URL          string
 	Checksum     string
-	ChecksumAlgo string
+	ChecksumAlgo string // "SHA256", "SHA512" or "SHA1" (legacy mirrors)
 	Size         int64
 	FileName     string
+	SignatureURL string    // Detached OpenPGP signature, empty if the distributor publishes none
+	Version      string    // Full version of the build, e.g. "21.0.5+11"
+	Arch         string    // Architecture in the distributor's naming, e.g. "x64"
+	ImageType    ImageType // Kind of image, see ImageTypes
 }
@@ -0,0 +1,75 @@
+package installer
+
+import (
+	"context"
+	"testing"
+)
+
+// fakeDistributor lists fixed builds and nothing else
+type fakeDistributor struct {
+	Distributor
+	builds []JavaBuild
+}
+
+func (f *fakeDistributor) GetBuilds(ctx context.Context, major string, arch string, imageType ImageType) ([]JavaBuild, error) {
+	var builds []JavaBuild
+	for _, build := range f.builds {
+		if MajorVersion(build.Version) == major {
+			builds = append(builds, build)
+		}
+	}
+	return builds, nil
+}
+
+func TestIsFullVersion(t *testing.T) {
+	for spec, want := range map[string]bool{
+		"17.0.8+7":    true,
+		"17.0.8":      true,
+		"11.0.20.1+1": true,
+		"21+35":       true,
+		"8u382-b05":   true,
+		"8u382":       true,
+		"21":          false,
+		"17.0":        false,
+		"1.8":         false,
+		"17.0.8+":     false,
+		"jdk-21":      false,
+	} {
+		if got := IsFullVersion(spec); got != want {
+			t.Errorf("IsFullVersion(%q) = %v, want %v", spec, got, want)
+		}
+	}
+}
+
+func TestResolveBuild(t *testing.T) {
+	// Newest first, as distributors list them
+	distributor := &fakeDistributor{builds: []JavaBuild{
+		{Version: "17.0.8.1+1"},
+		{Version: "17.0.8+7"},
+		{Version: "17.0.8+6"},
+		{Version: "17.0.7+7"},
+		{Version: "8u382-b05"},
+		{Version: "8u38-b01"},
+	}}
+
+	tests := []struct {
+		spec    string
+		want    string
+		wantErr bool
+	}{
+		{spec: "17.0.8+6", want: "17.0.8+6"},
+		{spec: "8u382-b05", want: "8u382-b05"},
+		{spec: "17.0.8", want: "17.0.8+7"},
+		{spec: "17.0.8.1", want: "17.0.8.1+1"},
+		{spec: "8u382", want: "8u382-b05"},
+		{spec: "8u38", want: "8u38-b01"},
+		{spec: "17.0.9", wantErr: true},
+		{spec: "11.0.20", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := resolveBuild(context.Background(), distributor, tt.spec, "x64", ImageJDK)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("resolveBuild(%q) = %q, %v; want %q (error: %v)", tt.spec, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
@@ -1,255 +1,750 @@
 package installer
 
 import (
-	"archive/zip"
-	"crypto/sha256"
-	"encoding/hex"
+	"context"
+	"crypto/x509"
+	"errors"
 	"fmt"
+	"hash"
 	"io"
 	"net/http"
 	"os"
 	"path/filepath"
+	"runtime"
+	"strconv"
 	"strings"
 	"time"
 
-	tea "github.com/charmbracelet/bubbletea"
+	"jv/internal/certs"
+	"jv/internal/hooks"
+	"jv/internal/java"
+	"jv/internal/manifest"
+	"jv/internal/policy"
 )
 
-// DownloadFile downloads a file from URL with animated progress bar
-func DownloadFile(url string, destPath string) error {
-	out, err := os.Create(destPath)
-	if err != nil {
-		return fmt.Errorf("failed to create file: %w", err)
+const (
+	downloadMaxAttempts = 5
+	downloadBaseDelay   = 2 * time.Second
+	downloadMaxDelay    = 60 * time.Second
+)
+
+// downloadError describes a failed download attempt and whether it is worth retrying
+type downloadError struct {
+	err        error
+	retryable  bool
+	retryAfter time.Duration
+}
+
+func (e *downloadError) Error() string {
+	return e.err.Error()
+}
+
+func (e *downloadError) Unwrap() error {
+	return e.err
+}
+
+// downloadUI renders the progress of a download while it is retried and resumed
+type downloadUI interface {
+	// begin is called at the start of every attempt once the response is known.
+	// It returns the writer that tracks transferred bytes.
+	begin(total int64, offset int64) io.Writer
+	// printf reports a status line without corrupting the progress display
+	printf(format string, args ...interface{})
+	// finish reports the final outcome of the download
+	finish(err error)
+}
+
+// downloadSession keeps the state shared by all attempts of a single download
+type downloadSession struct {
+	url      string
+	partPath string
+	// validatorPath holds the ETag or Last-Modified of the partial file's
+	// source, sent as If-Range so that data from a changed file is never appended
+	validatorPath string
+	total         int64
+	ui            downloadUI
+	hasher        hash.Hash // nil when no checksum is expected
+	checksum      string
+	stream        *streamExtraction // nil unless the archive is unpacked while downloading
+}
+
+// DownloadFile downloads a file from URL with animated progress bar (or progress
+// lines, see ProgressMode).
+// Data is written to destPath+".part", which is renamed to destPath only once
+// the full length reported by the server has been received and, when checksum
+// is not empty, its digest (computed while streaming) matches. A mismatch
+// deletes the partial file and fails immediately. Other failed attempts are
+// retried with exponential backoff (honoring Retry-After on 429/503) and
+// resume from the partial file using HTTP Range requests. A partial file is
+// only resumed if the server's ETag or Last-Modified was recorded with it; the
+// request carries it in If-Range, so a file that changed is sent again whole.
+// Cancelling ctx (or pressing ctrl+c in the progress bar) aborts the request
+// and deletes the partial file.
+func DownloadFile(ctx context.Context, url string, destPath string, checksumAlgo string, checksum string) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	ui := newDownloadUI(cancel)
+	defer ui.close()
+
+	return download(ctx, url, destPath, checksumAlgo, checksum, ui, nil)
+}
+
+// download runs the retry loop of DownloadFile, reporting progress to ui.
+// When stream is not nil the downloaded bytes are also fed to it; it is
+// abandoned if the download fails or has to resume.
+func download(ctx context.Context, url string, destPath string, checksumAlgo string, checksum string, ui downloadUI, stream *streamExtraction) error {
+	s := &downloadSession{
+		url:           url,
+		partPath:      destPath + ".part",
+		validatorPath: destPath + ".part.validator",
+		total:         -1,
+		ui:            ui,
+		checksum:      checksum,
+		stream:        stream,
 	}
-	defer out.Close()
 
-	resp, err := http.Get(url)
-	if err != nil {
-		return fmt.Errorf("failed to download: %w", err)
+	if checksum != "" {
+		hasher, err := newHasher(checksumAlgo, checksum)
+		if err != nil {
+			ui.finish(err)
+			return err
+		}
+		s.hasher = hasher
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("download failed with status: %d", resp.StatusCode)
+	var lastErr error
+retry:
+	for attempt := 1; attempt <= downloadMaxAttempts; attempt++ {
+		err := s.attempt(ctx)
+		if err == nil {
+			lastErr = nil
+			break
+		}
+		lastErr = err
+		if ctx.Err() != nil {
+			lastErr = ctx.Err()
+			break
+		}
+
+		var dlErr *downloadError
+		if !errors.As(err, &dlErr) || !dlErr.retryable || attempt == downloadMaxAttempts {
+			break
+		}
+
+		delay := backoffDelay(attempt)
+		if dlErr.retryAfter > 0 {
+			delay = dlErr.retryAfter
+		}
+		ui.printf("Download interrupted (%v), retrying in %s (attempt %d/%d)...",
+			err, delay.Round(time.Second), attempt+1, downloadMaxAttempts)
+		select {
+		case <-ctx.Done():
+			lastErr = ctx.Err()
+			break retry
+		case <-time.After(delay):
+		}
 	}
 
-	totalSize := resp.ContentLength
+	if errors.Is(lastErr, context.Canceled) || errors.Is(lastErr, context.DeadlineExceeded) {
+		// An interrupted install leaves nothing behind
+		s.removePart()
+	}
 
-	// Create progress model
-	progressModel := NewProgressModel(totalSize)
-	p := tea.NewProgram(progressModel)
+	if lastErr == nil && s.hasher != nil {
+		if err := checkDigest(s.hasher, s.checksum); err != nil {
+			// Never keep (or resume) data that failed verification
+			s.removePart()
+			lastErr = fmt.Errorf("checksum verification failed: %w", err)
+		}
+	}
 
-	// Create progress writer
-	pw := newProgressWriter(totalSize, p)
+	if lastErr == nil {
+		if err := os.Rename(s.partPath, destPath); err != nil {
+			lastErr = fmt.Errorf("failed to finalize download: %w", err)
+		}
+		os.Remove(s.validatorPath)
+	}
+
+	if lastErr != nil && stream != nil {
+		// Never keep files extracted from data that failed verification
+		stream.abandon()
+	}
+
+	ui.finish(lastErr)
+	return lastErr
+}
+
+// attempt performs a single HTTP request, resuming from the partial file if present
+func (s *downloadSession) attempt(ctx context.Context) error {
+	var offset int64
+	if info, err := os.Stat(s.partPath); err == nil {
+		offset = info.Size()
+	}
 
-	// Start the progress UI in a goroutine
-	go func() {
-		if _, err := p.Run(); err != nil {
-			fmt.Printf("Error running progress: %v\n", err)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	if offset > 0 {
+		// Without a validator the partial data cannot be matched to the remote file
+		if validator, err := os.ReadFile(s.validatorPath); err == nil && len(validator) > 0 {
+			req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+			req.Header.Set("If-Range", string(validator))
 		}
-	}()
+	}
 
-	// Give the UI a moment to start
-	time.Sleep(100 * time.Millisecond)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return &downloadError{err: fmt.Errorf("failed to download: %w", err), retryable: true}
+	}
+	defer resp.Body.Close()
 
-	// Create multi-writer: write to file AND progress tracker
-	multiWriter := io.MultiWriter(out, pw)
+	flags := os.O_WRONLY | os.O_CREATE
+	switch resp.StatusCode {
+	case http.StatusOK:
+		// Server sent the whole file (no partial data, Range not supported or
+		// the file changed since the partial data was received)
+		offset = 0
+		flags |= os.O_TRUNC
+		s.total = resp.ContentLength
+		if err := s.saveValidator(resp.Header); err != nil {
+			return err
+		}
 
-	// Download with progress
-	written, err := io.Copy(multiWriter, resp.Body)
+	case http.StatusPartialContent:
+		start, total, ok := parseContentRange(resp.Header.Get("Content-Range"))
+		if !ok || start != offset {
+			// Unexpected range: discard partial data and start over
+			s.removePart()
+			return &downloadError{err: fmt.Errorf("server returned unexpected range %q", resp.Header.Get("Content-Range")), retryable: true}
+		}
+		flags |= os.O_APPEND
+		s.total = total
+
+	case http.StatusRequestedRangeNotSatisfiable:
+		// The partial file is already complete, or larger than the remote file
+		_, total, ok := parseContentRange(resp.Header.Get("Content-Range"))
+		if ok && total == offset {
+			s.total = total
+			return s.resetHash(offset)
+		}
+		s.removePart()
+		return &downloadError{err: fmt.Errorf("partial download does not match remote file"), retryable: true}
+
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		return &downloadError{
+			err:        fmt.Errorf("download failed with status: %d", resp.StatusCode),
+			retryable:  true,
+			retryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
+		}
+
+	default:
+		return &downloadError{
+			err:       fmt.Errorf("download failed with status: %d", resp.StatusCode),
+			retryable: resp.StatusCode >= 500 || resp.StatusCode == http.StatusRequestTimeout,
+		}
+	}
+
+	out, err := os.OpenFile(s.partPath, flags, 0644)
 	if err != nil {
-		p.Send(progressErrMsg{err: err})
-		p.Quit()
-		return fmt.Errorf("failed to write file: %w", err)
+		return fmt.Errorf("failed to create file: %w", err)
 	}
+	defer out.Close()
 
-	if written != totalSize {
-		err := fmt.Errorf("incomplete download: got %d bytes, expected %d", written, totalSize)
-		p.Send(progressErrMsg{err: err})
-		p.Quit()
+	if err := s.resetHash(offset); err != nil {
 		return err
 	}
 
-	// Signal completion
-	p.Send(downloadCompleteMsg{})
+	// Create multi-writer: write to file, progress tracker AND hasher
+	writers := []io.Writer{out, s.ui.begin(s.total, offset)}
+	if s.hasher != nil {
+		writers = append(writers, s.hasher)
+	}
+	if s.stream != nil {
+		if w := s.stream.writer(offset); w != nil {
+			writers = append(writers, w)
+		}
+	}
+	multiWriter := io.MultiWriter(writers...)
+
+	// Download with progress
+	written, err := io.Copy(multiWriter, resp.Body)
+	if err != nil {
+		return &downloadError{err: fmt.Errorf("failed to write file: %w", err), retryable: true}
+	}
 
-	// Wait a moment for UI to finish
-	time.Sleep(200 * time.Millisecond)
+	if s.total > 0 && offset+written != s.total {
+		return &downloadError{
+			err:       fmt.Errorf("incomplete download: got %d bytes, expected %d", offset+written, s.total),
+			retryable: true,
+		}
+	}
 
 	return nil
 }
 
-// VerifyChecksum verifies the SHA256 checksum of a file
-func VerifyChecksum(filePath string, expectedChecksum string) error {
-	file, err := os.Open(filePath)
-	if err != nil {
-		return fmt.Errorf("failed to open file: %w", err)
+// saveValidator records the validator of a response sending the whole file,
+// preferring a strong ETag: weak ETags cannot be used in If-Range
+func (s *downloadSession) saveValidator(header http.Header) error {
+	validator := header.Get("ETag")
+	if validator == "" || strings.HasPrefix(validator, "W/") {
+		validator = header.Get("Last-Modified")
+	}
+	if validator == "" {
+		os.Remove(s.validatorPath)
+		return nil
+	}
+	if err := os.WriteFile(s.validatorPath, []byte(validator), 0644); err != nil {
+		return fmt.Errorf("failed to record download validator: %w", err)
 	}
-	defer file.Close()
+	return nil
+}
+
+// removePart deletes the partial file and its validator
+func (s *downloadSession) removePart() {
+	os.Remove(s.partPath)
+	os.Remove(s.validatorPath)
+}
 
-	hasher := sha256.New()
-	if _, err := io.Copy(hasher, file); err != nil {
-		return fmt.Errorf("failed to calculate checksum: %w", err)
+// resetHash seeds the hasher with the first offset bytes already present in the partial file
+func (s *downloadSession) resetHash(offset int64) error {
+	if s.hasher == nil {
+		return nil
 	}
 
-	actualChecksum := hex.EncodeToString(hasher.Sum(nil))
-	if !strings.EqualFold(actualChecksum, expectedChecksum) {
-		return fmt.Errorf("checksum mismatch: expected %s, got %s", expectedChecksum, actualChecksum)
+	s.hasher.Reset()
+	if offset == 0 {
+		return nil
 	}
 
+	part, err := os.Open(s.partPath)
+	if err != nil {
+		return fmt.Errorf("failed to read partial download: %w", err)
+	}
+	defer part.Close()
+
+	if _, err := io.CopyN(s.hasher, part, offset); err != nil {
+		return fmt.Errorf("failed to hash partial download: %w", err)
+	}
 	return nil
 }
 
-// ExtractZip extracts a ZIP file to the destination directory
-func ExtractZip(zipPath string, destDir string) (string, error) {
-	reader, err := zip.OpenReader(zipPath)
-	if err != nil {
-		return "", fmt.Errorf("failed to open zip: %w", err)
-	}
-	defer reader.Close()
-
-	// Find the root directory in the ZIP (usually jdk-xxx)
-	var rootDir string
-	for _, file := range reader.File {
-		if file.FileInfo().IsDir() && strings.HasPrefix(file.Name, "jdk") {
-			parts := strings.Split(file.Name, "/")
-			if len(parts) > 0 {
-				rootDir = parts[0]
-				break
-			}
-		}
+// backoffDelay returns the exponential backoff delay for the given attempt (1-based)
+func backoffDelay(attempt int) time.Duration {
+	delay := downloadBaseDelay << (attempt - 1)
+	if delay > downloadMaxDelay {
+		delay = downloadMaxDelay
 	}
+	return delay
+}
 
-	extractedPath := ""
-
-	for _, file := range reader.File {
-		filePath := filepath.Join(destDir, file.Name)
+// parseRetryAfter parses a Retry-After header given either in seconds or as an HTTP date
+func parseRetryAfter(value string) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
 
-		if file.FileInfo().IsDir() {
-			os.MkdirAll(filePath, os.ModePerm)
-			continue
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		delay := time.Duration(seconds) * time.Second
+		if delay > downloadMaxDelay {
+			delay = downloadMaxDelay
 		}
+		return delay
+	}
 
-		if err := os.MkdirAll(filepath.Dir(filePath), os.ModePerm); err != nil {
-			return "", fmt.Errorf("failed to create directory: %w", err)
+	if when, err := http.ParseTime(value); err == nil {
+		delay := time.Until(when)
+		if delay < 0 {
+			return 0
 		}
-
-		outFile, err := os.OpenFile(filePath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, file.Mode())
-		if err != nil {
-			return "", fmt.Errorf("failed to create file: %w", err)
+		if delay > downloadMaxDelay {
+			delay = downloadMaxDelay
 		}
+		return delay
+	}
 
-		rc, err := file.Open()
-		if err != nil {
-			outFile.Close()
-			return "", fmt.Errorf("failed to open file in zip: %w", err)
-		}
+	return 0
+}
+
+// parseContentRange parses "bytes start-end/total" and "bytes */total" headers.
+// total is -1 when the server reports an unknown length.
+func parseContentRange(value string) (start int64, total int64, ok bool) {
+	value = strings.TrimSpace(value)
+	if !strings.HasPrefix(value, "bytes ") {
+		return 0, 0, false
+	}
 
-		_, err = io.Copy(outFile, rc)
-		outFile.Close()
-		rc.Close()
+	rangePart, totalPart, found := strings.Cut(strings.TrimPrefix(value, "bytes "), "/")
+	if !found {
+		return 0, 0, false
+	}
 
+	total = -1
+	if totalPart != "*" {
+		t, err := strconv.ParseInt(totalPart, 10, 64)
 		if err != nil {
-			return "", fmt.Errorf("failed to extract file: %w", err)
+			return 0, 0, false
 		}
+		total = t
 	}
 
-	if rootDir != "" {
-		extractedPath = filepath.Join(destDir, rootDir)
+	if rangePart == "*" {
+		return 0, total, true
 	}
 
-	return extractedPath, nil
+	startPart, _, found := strings.Cut(rangePart, "-")
+	if !found {
+		return 0, 0, false
+	}
+	start, err := strconv.ParseInt(startPart, 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+
+	return start, total, true
 }
 
-// InstallJDK orchestrates the download, verification, and extraction of a JDK
-func InstallJDK(downloadInfo *DownloadInfo, version string, distributor string, isSystemWide bool) (string, error) {
-	// Determine installation base directory
-	var installBase string
-	if isSystemWide {
-		// Use absolute path for system-wide installation
-		installBase = filepath.Join(`C:\Program Files`, distributor)
-	} else {
-		homeDir, err := os.UserHomeDir()
-		if err != nil {
-			return "", fmt.Errorf("failed to get home directory: %w", err)
-		}
-		installBase = filepath.Join(homeDir, ".jv")
+// InstallOptions controls where and how InstallJDK installs a JDK
+type InstallOptions struct {
+	SystemWide bool               // Install under Program Files instead of the user's home
+	Layout     string             // Directory template below the install root, see InstallDir
+	Cache      *Cache             // Verified archives are kept here and reused by later installs
+	Verifier   *SignatureVerifier // Checks the distributor's detached signature
+
+	CACerts            []*x509.Certificate // Added to the truststore of runtime images before they are moved into place
+	TruststorePassword string
+	Hooks              *hooks.Runner  // Runs pre_install right before the JDK is moved into place
+	Policy             *policy.Policy // Checked once a local archive's release file is read
+	ManifestDir        string         // File manifests for jv verify are recorded here; "" records none
+}
+
+// InstalledBuild describes a JDK jv has installed and where it came from
+type InstalledBuild struct {
+	Path        string
+	Vendor      string
+	Version     string // Major version, e.g. "21"
+	FullVersion string // e.g. "21.0.5+11"
+	VendorBuild string // IMPLEMENTOR_VERSION of the release file, e.g. "Temurin-21.0.5+11"
+	Arch        string
+	ImageType   ImageType
+
+	SourceURL string          // Download URL, or the path of a local archive
+	Checksum  string          // Digest of the archive, e.g. "sha256:<hex>"
+	Signature SignatureStatus // "" when the signature could not be checked
+}
+
+// InstallJDK orchestrates the download, verification, and extraction of a JDK.
+// version is the major version; the install directory follows opts.Layout.
+// When ctx is cancelled the install stops, partial files and the staging
+// directory are removed and any existing installation is left as it was.
+func InstallJDK(ctx context.Context, downloadInfo *DownloadInfo, version string, distributor string, opts InstallOptions) (*InstalledBuild, error) {
+	plan, err := PlanInstall(downloadInfo, version, distributor, opts)
+	if err != nil {
+		return nil, err
+	}
+	finalPath := plan.InstallPath
+
+	// Refuse to start rather than fail halfway through on a full disk
+	if err := CheckDiskSpace([]*InstallPlan{plan}); err != nil {
+		return nil, err
 	}
 
-	// Create installation directory
+	// Create installation directory; staging and locking happen next to the final path
+	installBase := filepath.Dir(finalPath)
 	if err := os.MkdirAll(installBase, 0755); err != nil {
-		return "", fmt.Errorf("failed to create installation directory: %w", err)
+		return nil, fmt.Errorf("failed to create installation directory: %w", err)
 	}
 
-	// Create temp directory for download
-	tempDir := filepath.Join(os.TempDir(), "jv-install")
-	if err := os.MkdirAll(tempDir, 0755); err != nil {
-		return "", fmt.Errorf("failed to create temp directory: %w", err)
+	// Stage into a directory unique to this run, on the same volume as the installation
+	stagingDir, err := newStagingDir(installBase)
+	if err != nil {
+		return nil, err
 	}
-	defer os.RemoveAll(tempDir)
+	defer os.RemoveAll(stagingDir)
 
-	// Download JDK
-	zipPath := filepath.Join(tempDir, downloadInfo.FileName)
-	fmt.Println("Downloading JDK...")
-	if err := DownloadFile(downloadInfo.URL, zipPath); err != nil {
-		return "", fmt.Errorf("download failed: %w", err)
+	tempExtractDir := filepath.Join(stagingDir, "extract")
+	archivePath, extractedPath, err := fetchArchive(ctx, downloadInfo, opts.Cache, tempExtractDir)
+	if err != nil {
+		return nil, err
 	}
 
-	// Verify checksum with spinner
-	var checksumErr error
-	spinnerErr := WithSpinner("Verifying checksum...", func() error {
-		checksumErr = VerifyChecksum(zipPath, downloadInfo.Checksum)
+	// Verify the vendor signature with spinner
+	var sigStatus SignatureStatus
+	var sigErr error
+	spinnerErr := WithSpinner(ctx, "Verifying signature...", func(ctx context.Context) error {
+		sigStatus, sigErr = opts.Verifier.Verify(ctx, distributor, archivePath, downloadInfo.SignatureURL)
 		return nil
 	})
 	if spinnerErr != nil {
-		return "", spinnerErr
+		return nil, spinnerErr
 	}
-	if checksumErr != nil {
-		return "", fmt.Errorf("checksum verification failed: %w", checksumErr)
+	if sigErr != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if sigStatus == "" {
+			// A bad signature means the cached archive cannot be trusted either
+			if entry, ok := opts.Cache.Lookup(downloadInfo.ChecksumAlgo, downloadInfo.Checksum); ok {
+				opts.Cache.Remove(entry)
+			}
+		}
+		return nil, fmt.Errorf("signature verification failed: %w", sigErr)
+	}
+	switch sigStatus {
+	case SignatureVerified:
+		fmt.Println("✓ Signature verified successfully")
+	case SignatureUnsigned:
+		fmt.Printf("⚠ %s publishes no signature for this archive (use --require-signature to refuse)\n", distributor)
+	case SignatureNoKey:
+		fmt.Printf("⚠ Signature not checked: no trusted key available for %s\n", distributor)
 	}
-	fmt.Println("✓ Checksum verified successfully")
 
-	// Extract to temp location with spinner
-	var extractedPath string
-	var extractErr error
-	tempExtractDir := filepath.Join(tempDir, "extract")
+	if extractedPath != "" {
+		fmt.Println("✓ JDK extracted while downloading")
+	} else {
+		// Extract to temp location with spinner
+		var extractErr error
+		spinnerErr = WithSpinner(ctx, "Extracting JDK...", func(ctx context.Context) error {
+			var err error
+			extractedPath, err = ExtractArchive(ctx, archivePath, downloadInfo.FileName, tempExtractDir)
+			extractErr = err
+			return nil
+		})
+		if spinnerErr != nil {
+			return nil, spinnerErr
+		}
+		if extractErr != nil {
+			return nil, fmt.Errorf("extraction failed: %w", extractErr)
+		}
+		fmt.Println("✓ JDK extracted successfully")
+	}
+
+	extractedPath, err = jdkRoot(extractedPath, downloadInfo.ImageType)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := importCACerts(extractedPath, downloadInfo.ImageType, opts); err != nil {
+		return nil, err
+	}
+
+	// Run the new JDK before it replaces anything; a failure discards the staging directory
+	if err := verifyInstall(ctx, extractedPath, downloadInfo.Version, plan.Arch, downloadInfo.ImageType, filepath.Join(stagingDir, "smoke")); err != nil {
+		return nil, err
+	}
+
+	target := hooks.Target{Path: finalPath, Version: downloadInfo.Version, Vendor: distributor, Arch: plan.Arch}
+	if err := opts.Hooks.Run(ctx, hooks.PreInstall, target); err != nil {
+		return nil, err
+	}
+
+	// Move to final location
+	if err := moveIntoPlace(ctx, extractedPath, finalPath); err != nil {
+		return nil, err
+	}
+
+	fmt.Printf("JDK installed successfully to: %s\n", finalPath)
+	recordManifest(ctx, finalPath, opts)
+
+	build := &InstalledBuild{
+		Path:        finalPath,
+		Vendor:      distributor,
+		Version:     version,
+		FullVersion: downloadInfo.Version,
+		Arch:        plan.Arch,
+		ImageType:   downloadInfo.ImageType,
+		SourceURL:   downloadInfo.URL,
+		Checksum:    formatChecksum(downloadInfo.ChecksumAlgo, downloadInfo.Checksum),
+		Signature:   sigStatus,
+	}
+	if release, err := java.ReadReleaseFile(finalPath); err == nil {
+		build.VendorBuild = release["IMPLEMENTOR_VERSION"]
+		if fullVersion := releaseFullVersion(release); fullVersion != "" && build.FullVersion == "" {
+			build.FullVersion = fullVersion
+		}
+	}
+	return build, nil
+}
+
+// jdkRoot returns the directory to install from an extracted archive and checks
+// that runtime images contain the java launcher
+func jdkRoot(extractedPath string, imageType ImageType) (string, error) {
+	// macOS archives keep the JDK below Contents/Home
+	if home := filepath.Join(extractedPath, "Contents", "Home"); isDir(home) {
+		extractedPath = home
+	}
+
+	// Verify the java launcher exists (other images have no bin directory)
+	if imageType.IsRuntime() {
+		javaExe := filepath.Join(extractedPath, "bin", javaBinaryName())
+		if _, err := os.Stat(javaExe); os.IsNotExist(err) {
+			return "", fmt.Errorf("invalid JDK structure: bin%c%s not found", filepath.Separator, javaBinaryName())
+		}
+	}
+
+	return extractedPath, nil
+}
+
+// recordManifest hashes the files of a finished installation for jv verify.
+// The JDK is already in place, so a failure is only reported.
+func recordManifest(ctx context.Context, jdkPath string, opts InstallOptions) {
+	if opts.ManifestDir == "" {
+		return
+	}
 
-	spinnerErr = WithSpinner("Extracting JDK...", func() error {
-		var err error
-		extractedPath, err = ExtractZip(zipPath, tempExtractDir)
-		extractErr = err
+	var m *manifest.Manifest
+	var buildErr error
+	spinnerErr := WithSpinner(ctx, "Recording file manifest...", func(ctx context.Context) error {
+		m, buildErr = manifest.Build(ctx, jdkPath)
 		return nil
 	})
-	if spinnerErr != nil {
-		return "", spinnerErr
+	if spinnerErr == nil && buildErr == nil {
+		buildErr = m.Save(opts.ManifestDir)
 	}
-	if extractErr != nil {
-		return "", fmt.Errorf("extraction failed: %w", extractErr)
+	if err := errors.Join(spinnerErr, buildErr); err != nil {
+		fmt.Printf("⚠ Could not record file manifest, jv verify will not check this JDK: %v\n", err)
+		return
 	}
-	fmt.Println("✓ JDK extracted successfully")
+	fmt.Printf("✓ Recorded file manifest (%d files)\n", len(m.Files))
+}
 
-	// Verify java.exe exists
-	javaExe := filepath.Join(extractedPath, "bin", "java.exe")
-	if _, err := os.Stat(javaExe); os.IsNotExist(err) {
-		return "", fmt.Errorf("invalid JDK structure: bin\\java.exe not found")
+// importCACerts adds the configured CA certificates to the truststore of a JDK
+func importCACerts(jdkPath string, imageType ImageType, opts InstallOptions) error {
+	if len(opts.CACerts) == 0 || !imageType.IsRuntime() {
+		return nil
 	}
 
-	// Move to final location
-	finalPath := filepath.Join(installBase, fmt.Sprintf("jdk-%s", version))
+	added, err := certs.Import(jdkPath, opts.CACerts, opts.TruststorePassword)
+	if err != nil {
+		return fmt.Errorf("failed to import CA certificates: %w", err)
+	}
+	if added > 0 {
+		fmt.Printf("✓ Imported %d CA certificate(s) into cacerts\n", added)
+	}
+	return nil
+}
+
+// moveIntoPlace swaps a staged JDK into finalPath while holding the lock on its
+// parent directory. It is the last point at which an install can be cancelled.
+func moveIntoPlace(ctx context.Context, stagedPath string, finalPath string) error {
+	unlock, err := lockInstallRoot(ctx, filepath.Dir(finalPath))
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 
-	// Remove old installation if exists
 	if _, err := os.Stat(finalPath); err == nil {
-		fmt.Printf("Removing existing installation at %s\n", finalPath)
-		if err := os.RemoveAll(finalPath); err != nil {
-			return "", fmt.Errorf("failed to remove old installation: %w", err)
+		fmt.Printf("Replacing existing installation at %s\n", finalPath)
+	}
+	return swapInstall(stagedPath, finalPath)
+}
+
+// InstallRoot returns the directory below which install_layout is applied:
+// C:\Program Files for system-wide installs, %USERPROFILE%\.jv otherwise
+func InstallRoot(systemWide bool) (string, error) {
+	if systemWide {
+		return `C:\Program Files`, nil
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".jv"), nil
+}
+
+// javaBinaryName returns the file name of the java launcher on this platform
+func javaBinaryName() string {
+	if runtime.GOOS == "windows" {
+		return "java.exe"
+	}
+	return "java"
+}
+
+// isDir reports whether path exists and is a directory
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
+
+// fetchArchive returns the path of a verified archive for downloadInfo,
+// reusing the cached copy when one exists and downloading it otherwise.
+// A tar archive with a published checksum is unpacked into extractDir while
+// it downloads; the extracted root is returned in that case, "" otherwise.
+func fetchArchive(ctx context.Context, downloadInfo *DownloadInfo, cache *Cache, extractDir string) (string, string, error) {
+	if entry, ok := cache.Lookup(downloadInfo.ChecksumAlgo, downloadInfo.Checksum); ok {
+		// Entries were verified when stored; only re-hash if the file changed since
+		var checksumErr error
+		if !entry.Unchanged() {
+			spinnerErr := WithSpinner(ctx, "Verifying cached archive...", func(context.Context) error {
+				checksumErr = VerifyChecksum(entry.Path, downloadInfo.ChecksumAlgo, downloadInfo.Checksum)
+				return nil
+			})
+			if spinnerErr != nil {
+				return "", "", spinnerErr
+			}
+		}
+		if checksumErr == nil {
+			cache.Touch(entry)
+			fmt.Printf("✓ Using cached archive %s\n", entry.FileName)
+			return entry.Path, "", nil
 		}
+
+		// Corrupted cache entry: drop it and download again
+		fmt.Printf("Cached archive is corrupted, downloading again: %v\n", checksumErr)
+		cache.Remove(entry)
 	}
 
-	// Move extracted directory to final location
-	if err := os.Rename(extractedPath, finalPath); err != nil {
-		return "", fmt.Errorf("failed to move JDK to final location: %w", err)
+	// Extraction can only follow the download if the result is verified afterwards
+	var stream *streamExtraction
+	if format := archiveFormatFromName(downloadInfo.FileName); format != "" && format != ArchiveZip && downloadInfo.Checksum != "" {
+		stream = newStreamExtraction(ctx, format, extractDir)
 	}
 
-	fmt.Printf("JDK installed successfully to: %s\n", finalPath)
-	return finalPath, nil
+	// Download JDK; ctrl+c in the progress bar cancels it
+	fmt.Println("Downloading JDK...")
+	downloadCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	ui := newDownloadUI(cancel)
+	entry, err := downloadToCache(downloadCtx, downloadInfo, cache, ui, stream)
+	ui.close()
+	if err != nil {
+		return "", "", fmt.Errorf("download failed: %w", err)
+	}
+	fmt.Println("✓ Checksum verified successfully")
+
+	var extractedPath string
+	if stream != nil {
+		extractedPath = stream.result()
+	}
+
+	return entry.Path, extractedPath, nil
+}
+
+// downloadToCache downloads and verifies an archive, then stores it in the cache.
+// Partial downloads live in the cache so a download broken off by network errors
+// can be resumed later; a cancelled download is deleted instead.
+func downloadToCache(ctx context.Context, downloadInfo *DownloadInfo, cache *Cache, ui downloadUI, stream *streamExtraction) (*CacheEntry, error) {
+	zipPath, err := cache.PartialPath(downloadInfo.FileName)
+	if err != nil {
+		ui.finish(err)
+		return nil, err
+	}
+
+	if err := download(ctx, downloadInfo.URL, zipPath, downloadInfo.ChecksumAlgo, downloadInfo.Checksum, ui, stream); err != nil {
+		return nil, err
+	}
+
+	// Keep the verified archive for later installs
+	entry, err := cache.Store(zipPath, downloadInfo)
+	if err != nil && stream != nil {
+		stream.abandon()
+	}
+	return entry, err
 }
@@ -0,0 +1,74 @@
+package installer
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDownloadResume(t *testing.T) {
+	content := []byte("the whole archive, as the server has it now")
+	modified := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		part      string
+		validator string // "" for a partial file recorded without one
+		wantRange bool
+		resumed   bool
+	}{
+		// The partial data differs in case only, to tell a resume from a restart
+		{"matching validator", "THE WHOLE ", `"v2"`, true, true},
+		{"matching last-modified", "THE WHOLE ", modified.Format(http.TimeFormat), true, true},
+		{"changed file", "stale data from an older archive", `"v1"`, true, false},
+		{"no validator", "stale data from an older archive", "", false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotRange bool
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotRange = r.Header.Get("Range") != ""
+				if gotRange && r.Header.Get("If-Range") == "" {
+					t.Error("Range was sent without If-Range")
+				}
+				if tt.name != "matching last-modified" {
+					w.Header().Set("ETag", `"v2"`)
+				}
+				http.ServeContent(w, r, "jdk.zip", modified, bytes.NewReader(content))
+			}))
+			defer server.Close()
+
+			destPath := filepath.Join(t.TempDir(), "jdk.zip")
+			os.WriteFile(destPath+".part", []byte(tt.part), 0644)
+			if tt.validator != "" {
+				os.WriteFile(destPath+".part.validator", []byte(tt.validator), 0644)
+			}
+
+			if err := download(context.Background(), server.URL, destPath, "", "", &lineUI{quiet: true}, nil); err != nil {
+				t.Fatal(err)
+			}
+			if gotRange != tt.wantRange {
+				t.Errorf("sent Range = %v, want %v", gotRange, tt.wantRange)
+			}
+			want := content
+			if tt.resumed {
+				want = append([]byte(tt.part), content[len(tt.part):]...)
+			}
+			data, err := os.ReadFile(destPath)
+			if err != nil || !bytes.Equal(data, want) {
+				t.Errorf("downloaded %q, %v; want %q", data, err, want)
+			}
+			for _, leftover := range []string{destPath + ".part", destPath + ".part.validator"} {
+				if _, err := os.Stat(leftover); !os.IsNotExist(err) {
+					t.Errorf("%s was left behind", filepath.Base(leftover))
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,334 @@
+package installer
+
+import (
+	"archive/zip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// maxExtractedSize bounds the total uncompressed size of an archive.
+// Full JDK images are a few hundred MB; debug images stay well below this.
+const maxExtractedSize int64 = 4 << 30
+
+// extractor writes archive entries below destDir, rejecting entries that would
+// escape it and enforcing maxExtractedSize on the bytes actually written
+type extractor struct {
+	ctx       context.Context
+	destDir   string
+	written   int64
+	limit     int64
+	links     []pendingLink
+	hardlinks []pendingLink
+	topLevel  map[string]bool
+}
+
+// pendingLink is a symlink entry, created only after all regular files are
+// written so that no entry can be extracted through a link
+type pendingLink struct {
+	path   string
+	target string
+}
+
+func newExtractor(ctx context.Context, destDir string) (*extractor, error) {
+	destDir, err := filepath.Abs(destDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve destination: %w", err)
+	}
+	if err := os.MkdirAll(destDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	return &extractor{
+		ctx:      ctx,
+		destDir:  destDir,
+		limit:    maxExtractedSize,
+		topLevel: make(map[string]bool),
+	}, nil
+}
+
+// entryPath validates an archive entry name and returns where it should be written.
+// Absolute paths, drive letters and ".." components are rejected (zip-slip).
+func (x *extractor) entryPath(name string) (string, error) {
+	clean := path.Clean(strings.ReplaceAll(name, "\\", "/"))
+	clean = strings.TrimPrefix(clean, "./")
+	if clean == "." || clean == "" {
+		return "", nil
+	}
+
+	native := filepath.FromSlash(clean)
+	if strings.HasPrefix(clean, "/") || filepath.VolumeName(native) != "" || !filepath.IsLocal(native) {
+		return "", fmt.Errorf("illegal path in archive: %s", name)
+	}
+
+	x.topLevel[strings.SplitN(clean, "/", 2)[0]] = true
+	return filepath.Join(x.destDir, native), nil
+}
+
+// mkdir creates a directory entry
+func (x *extractor) mkdir(name string) error {
+	target, err := x.entryPath(name)
+	if err != nil || target == "" {
+		return err
+	}
+	if err := os.MkdirAll(target, 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	return nil
+}
+
+// writeFile creates a regular file entry, preserving its permission bits
+func (x *extractor) writeFile(name string, mode os.FileMode, r io.Reader) error {
+	target, err := x.entryPath(name)
+	if err != nil || target == "" {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	// Keep the archived permissions, but always let the owner replace the file later
+	perm := mode.Perm() | 0600
+	if mode.Perm() == 0 {
+		perm = 0644
+	}
+
+	outFile, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+
+	// Read one byte past the remaining budget to detect oversized archives
+	n, err := io.Copy(outFile, contextReader{x.ctx, io.LimitReader(r, x.limit-x.written+1)})
+	closeErr := outFile.Close()
+	x.written += n
+	if err != nil {
+		return fmt.Errorf("failed to extract file: %w", err)
+	}
+	if closeErr != nil {
+		return fmt.Errorf("failed to extract file: %w", closeErr)
+	}
+	if x.written > x.limit {
+		return fmt.Errorf("archive exceeds maximum uncompressed size of %s", FormatSize(x.limit))
+	}
+
+	return nil
+}
+
+// symlink records a symlink entry. The target must stay inside destDir.
+func (x *extractor) symlink(name string, target string) error {
+	linkPath, err := x.entryPath(name)
+	if err != nil || linkPath == "" {
+		return err
+	}
+
+	target = filepath.FromSlash(strings.ReplaceAll(target, "\\", "/"))
+	if filepath.IsAbs(target) || filepath.VolumeName(target) != "" {
+		return fmt.Errorf("illegal symlink in archive: %s -> %s", name, target)
+	}
+
+	resolved := filepath.Join(filepath.Dir(linkPath), target)
+	rel, err := filepath.Rel(x.destDir, resolved)
+	if err != nil || !filepath.IsLocal(rel) {
+		return fmt.Errorf("illegal symlink in archive: %s -> %s", name, target)
+	}
+
+	x.links = append(x.links, pendingLink{path: linkPath, target: target})
+	return nil
+}
+
+// hardlink records a hard link entry. Unlike symlink targets, the target is
+// relative to the archive root and must be a file extracted from the same archive.
+func (x *extractor) hardlink(name string, target string) error {
+	linkPath, err := x.entryPath(name)
+	if err != nil || linkPath == "" {
+		return err
+	}
+
+	targetPath, err := x.entryPath(target)
+	if err != nil || targetPath == "" {
+		return fmt.Errorf("illegal hard link in archive: %s -> %s", name, target)
+	}
+
+	x.hardlinks = append(x.hardlinks, pendingLink{path: linkPath, target: targetPath})
+	return nil
+}
+
+// finish creates the recorded symlinks and returns the extracted root directory:
+// the single top-level directory of the archive whatever its name (jdk-21...,
+// zulu17..., amazon-corretto-...), or destDir itself if there is none.
+func (x *extractor) finish() (string, error) {
+	if err := x.checkLinks(); err != nil {
+		return "", err
+	}
+
+	for _, link := range x.hardlinks {
+		if err := os.MkdirAll(filepath.Dir(link.path), 0755); err != nil {
+			return "", fmt.Errorf("failed to create directory: %w", err)
+		}
+		os.Remove(link.path)
+		if err := os.Link(link.target, link.path); err != nil {
+			if copyErr := copyFile(link.target, link.path); copyErr != nil {
+				return "", fmt.Errorf("failed to create link %s: %w", link.path, copyErr)
+			}
+		}
+	}
+
+	for _, link := range x.links {
+		if err := os.MkdirAll(filepath.Dir(link.path), 0755); err != nil {
+			return "", fmt.Errorf("failed to create directory: %w", err)
+		}
+		os.Remove(link.path)
+		if err := os.Symlink(link.target, link.path); err != nil {
+			// Creating symlinks needs extra privileges on Windows; copy regular files instead
+			source := filepath.Join(filepath.Dir(link.path), link.target)
+			if info, statErr := os.Stat(source); statErr == nil && info.Mode().IsRegular() {
+				if copyErr := copyFile(source, link.path); copyErr != nil {
+					return "", fmt.Errorf("failed to create link %s: %w", link.path, copyErr)
+				}
+				continue
+			}
+			return "", fmt.Errorf("failed to create link %s: %w", link.path, err)
+		}
+	}
+
+	if len(x.topLevel) == 1 {
+		for name := range x.topLevel {
+			root := filepath.Join(x.destDir, filepath.FromSlash(name))
+			if info, err := os.Stat(root); err == nil && info.IsDir() {
+				return root, nil
+			}
+		}
+	}
+
+	return x.destDir, nil
+}
+
+// checkLinks refuses symlinks that could resolve outside destDir through
+// another link. Each target is only checked as a path string by symlink, so a
+// link placed below another link, or whose target passes through one (such as
+// x -> a/b/c/.. with a/b/c -> ../..), is rejected before any link is created.
+func (x *extractor) checkLinks() error {
+	linkPaths := make(map[string]bool, len(x.links))
+	for _, link := range x.links {
+		linkPaths[strings.ToLower(link.path)] = true
+	}
+
+	for _, link := range x.links {
+		for dir := filepath.Dir(link.path); len(dir) > len(x.destDir); dir = filepath.Dir(dir) {
+			if linkPaths[strings.ToLower(dir)] {
+				return fmt.Errorf("illegal nested symlink in archive: %s", link.path)
+			}
+		}
+
+		// Resolve the target one component at a time; only the last may be a link
+		current := filepath.Dir(link.path)
+		parts := strings.Split(link.target, string(filepath.Separator))
+		for idx, part := range parts {
+			switch part {
+			case "", ".":
+				continue
+			case "..":
+				current = filepath.Dir(current)
+			default:
+				current = filepath.Join(current, part)
+			}
+			rel, err := filepath.Rel(x.destDir, current)
+			if err != nil || !filepath.IsLocal(rel) {
+				return fmt.Errorf("illegal symlink in archive: %s -> %s", link.path, link.target)
+			}
+			if idx < len(parts)-1 && linkPaths[strings.ToLower(current)] {
+				return fmt.Errorf("illegal symlink in archive: %s -> %s passes through another link", link.path, link.target)
+			}
+		}
+	}
+	return nil
+}
+
+// contextReader fails reads once ctx is cancelled, so that copying a large
+// entry stops promptly
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (r contextReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.r.Read(p)
+}
+
+// ExtractZip extracts a ZIP file to the destination directory and returns the
+// path of the extracted JDK root. Extraction stops when ctx is cancelled; the
+// caller removes whatever was written.
+func ExtractZip(ctx context.Context, zipPath string, destDir string) (string, error) {
+	reader, err := zip.OpenReader(zipPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open zip: %w", err)
+	}
+	defer reader.Close()
+
+	x, err := newExtractor(ctx, destDir)
+	if err != nil {
+		return "", err
+	}
+
+	for _, file := range reader.File {
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+		mode := file.Mode()
+
+		switch {
+		case mode.IsDir():
+			err = x.mkdir(file.Name)
+
+		case mode&os.ModeSymlink != 0:
+			var target string
+			target, err = readZipLink(file)
+			if err == nil {
+				err = x.symlink(file.Name, target)
+			}
+
+		case mode.IsRegular():
+			var rc io.ReadCloser
+			rc, err = file.Open()
+			if err != nil {
+				return "", fmt.Errorf("failed to open file in zip: %w", err)
+			}
+			err = x.writeFile(file.Name, mode, rc)
+			rc.Close()
+
+		default:
+			// Devices, pipes and other special files have no place in a JDK
+			err = fmt.Errorf("unsupported entry type in archive: %s", file.Name)
+		}
+
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return x.finish()
+}
+
+// readZipLink reads the target of a symlink entry, stored as the entry's content
+func readZipLink(file *zip.File) (string, error) {
+	rc, err := file.Open()
+	if err != nil {
+		return "", fmt.Errorf("failed to open file in zip: %w", err)
+	}
+	defer rc.Close()
+
+	target, err := io.ReadAll(io.LimitReader(rc, 4096))
+	if err != nil {
+		return "", fmt.Errorf("failed to read link in zip: %w", err)
+	}
+	return string(target), nil
+}
@@ -0,0 +1,178 @@
+package installer
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// tarEntry is one entry of a test archive; Link makes it a symlink, Hardlink a hard link
+type tarEntry struct {
+	Name     string
+	Body     string
+	Link     string
+	Hardlink string
+}
+
+func buildTarGz(t *testing.T, entries []tarEntry) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for _, entry := range entries {
+		header := &tar.Header{Name: entry.Name, Mode: 0644, Typeflag: tar.TypeReg, Size: int64(len(entry.Body))}
+		switch {
+		case strings.HasSuffix(entry.Name, "/"):
+			header = &tar.Header{Name: entry.Name, Mode: 0755, Typeflag: tar.TypeDir}
+		case entry.Link != "":
+			header = &tar.Header{Name: entry.Name, Mode: 0777, Typeflag: tar.TypeSymlink, Linkname: entry.Link}
+		case entry.Hardlink != "":
+			header = &tar.Header{Name: entry.Name, Mode: 0644, Typeflag: tar.TypeLink, Linkname: entry.Hardlink}
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+		if header.Typeflag == tar.TypeReg {
+			if _, err := tw.Write([]byte(entry.Body)); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return &buf
+}
+
+// assertContained fails when anything besides the destination directory was written to root
+func assertContained(t *testing.T, root string) {
+	t.Helper()
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range entries {
+		if entry.Name() != "dest" {
+			t.Errorf("extraction wrote %s outside the destination", entry.Name())
+		}
+	}
+}
+
+func TestExtractTarRejectsEscapes(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []tarEntry
+	}{
+		{"parent directory", []tarEntry{{Name: "../evil", Body: "x"}}},
+		{"nested parent directory", []tarEntry{{Name: "jdk/../../evil", Body: "x"}}},
+		{"absolute path", []tarEntry{{Name: "/tmp/evil", Body: "x"}}},
+		{"drive letter", []tarEntry{{Name: "C:/evil", Body: "x"}}},
+		{"backslashes", []tarEntry{{Name: "jdk\\..\\..\\evil", Body: "x"}}},
+		{"symlink to parent", []tarEntry{{Name: "jdk/link", Link: "../../outside"}}},
+		{"absolute symlink", []tarEntry{{Name: "jdk/link", Link: "/etc/passwd"}}},
+		{"symlink below symlink", []tarEntry{
+			{Name: "jdk/dir", Link: "."},
+			{Name: "jdk/dir/sub", Link: "../.."},
+		}},
+		{"symlink through symlink", []tarEntry{
+			{Name: "a/b/c", Link: "../.."},
+			{Name: "x", Link: "a/b/c/.."},
+		}},
+		{"symlink through symlink in subdirectory", []tarEntry{
+			{Name: "jdk/lib/up", Link: ".."},
+			{Name: "jdk/lib/security/cacerts", Link: "../up/../../outside"},
+		}},
+		{"hard link to parent", []tarEntry{{Name: "jdk/link", Hardlink: "../outside"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			archive := buildTarGz(t, tt.entries)
+			if _, err := ExtractTar(context.Background(), archive, ArchiveTarGz, filepath.Join(root, "dest")); err == nil {
+				t.Fatal("expected the archive to be rejected")
+			}
+			assertContained(t, root)
+		})
+	}
+}
+
+func TestExtractTarAllowsLocalLinks(t *testing.T) {
+	root := t.TempDir()
+	archive := buildTarGz(t, []tarEntry{
+		{Name: "jdk-21/"},
+		{Name: "jdk-21/lib/security/cacerts", Body: "certs"},
+		{Name: "jdk-21/cacerts", Link: "lib/security/cacerts"},
+		{Name: "jdk-21/release", Body: "JAVA_VERSION=\"21\""},
+		{Name: "jdk-21/release.copy", Hardlink: "jdk-21/release"},
+	})
+
+	jdkPath, err := ExtractTar(context.Background(), archive, ArchiveTarGz, filepath.Join(root, "dest"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Base(jdkPath) != "jdk-21" {
+		t.Errorf("extracted root = %s, want the jdk-21 directory", jdkPath)
+	}
+	for name, want := range map[string]string{"cacerts": "certs", "release.copy": "JAVA_VERSION=\"21\""} {
+		data, err := os.ReadFile(filepath.Join(jdkPath, name))
+		if err != nil || string(data) != want {
+			t.Errorf("%s = %q, %v; want %q", name, data, err, want)
+		}
+	}
+	assertContained(t, root)
+}
+
+func TestExtractZipRejectsEscapes(t *testing.T) {
+	tests := []struct {
+		name   string
+		file   string
+		target string // Symlink target, "" for a regular file
+	}{
+		{"parent directory", "../evil", ""},
+		{"absolute path", "/evil", ""},
+		{"symlink to parent", "jdk/link", "../../outside"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			zipPath := filepath.Join(root, "jdk.zip")
+			out, err := os.Create(zipPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			zw := zip.NewWriter(out)
+			header := &zip.FileHeader{Name: tt.file, Method: zip.Deflate}
+			header.SetMode(0644)
+			body := "x"
+			if tt.target != "" {
+				header.SetMode(os.ModeSymlink | 0777)
+				body = tt.target
+			}
+			w, err := zw.CreateHeader(header)
+			if err != nil {
+				t.Fatal(err)
+			}
+			w.Write([]byte(body))
+			zw.Close()
+			out.Close()
+
+			// The archive itself lives in root, so only dest may be added next to it
+			if _, err := ExtractZip(context.Background(), zipPath, filepath.Join(root, "dest")); err == nil {
+				t.Fatal("expected the archive to be rejected")
+			}
+			os.Remove(zipPath)
+			assertContained(t, root)
+		})
+	}
+}
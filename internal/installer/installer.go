@@ -1,51 +1,127 @@
 package installer
 
 import (
+	"context"
+	"crypto/x509"
 	"fmt"
 	"os"
-	"runtime"
+	"path/filepath"
 	"strings"
 	"time"
 
+	"jv/internal/certs"
 	"jv/internal/config"
 	"jv/internal/env"
+	"jv/internal/hooks"
 	"jv/internal/java"
+	"jv/internal/lifecycle"
+	"jv/internal/policy"
 	"jv/internal/theme"
 
 	"github.com/charmbracelet/huh"
 	"github.com/charmbracelet/lipgloss"
 )
 
+// Options holds the command-line settings of an installation run
+type Options struct {
+	RequireSignature bool      // Refuse archives without a valid vendor signature
+	ImageType        ImageType // Image to install; empty asks interactively (jdk when non-interactive)
+	Arch             string    // Architecture to install in vendor naming; empty for the host's
+	DryRun           bool      // Only show the install plan; download, install and configure nothing
+}
+
 // Installer handles the interactive Java installation process
 type Installer struct {
 	detector     *java.Detector
 	config       *config.Config
+	cache        *Cache
+	verifier     *SignatureVerifier
 	isAdmin      bool
+	imageType    ImageType
+	arch         string
+	dryRun       bool
+	caCerts      []*x509.Certificate
+	hooks        *hooks.Runner
+	lifecycle    *lifecycle.Dataset
+	policy       *policy.Policy
 	distributors map[int]Distributor
 }
 
 // NewInstaller creates a new Installer instance
-func NewInstaller(isAdmin bool) (*Installer, error) {
+func NewInstaller(isAdmin bool, opts Options) (*Installer, error) {
 	cfg, err := config.Load()
 	if err != nil {
 		return nil, fmt.Errorf("failed to load config: %w", err)
 	}
 
+	arch := java.HostArch()
+	if opts.Arch != "" {
+		if arch, err = java.NormalizeArch(opts.Arch); err != nil {
+			return nil, err
+		}
+	}
+
+	pol, err := policy.Load(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	// Corporate CA certificates every new JDK must trust
+	var caCerts []*x509.Certificate
+	if cfg.CABundle != "" {
+		if caCerts, err = certs.ReadPEMFile(cfg.CABundle); err != nil {
+			return nil, fmt.Errorf("failed to read ca_bundle: %w", err)
+		}
+	}
+
 	distributors := make(map[int]Distributor)
-	distributors[1] = NewAdoptiumDistributor()
-	// Future: distributors[2] = NewAzulDistributor()
-	// Future: distributors[3] = NewCorrettoDistributor()
+	for idx, distributor := range CatalogDistributors(cfg) {
+		distributors[idx+1] = distributor
+	}
 
 	return &Installer{
-		detector:     java.NewDetector(),
-		config:       cfg,
+		detector: java.NewDetector(),
+		config:   cfg,
+		cache:    NewCache(cfg.GetCacheDir()),
+		verifier: NewSignatureVerifier(
+			filepath.Join(cfg.GetCacheDir(), "keys"),
+			cfg.SignatureKeys,
+			opts.RequireSignature || cfg.RequireSignature,
+		),
 		isAdmin:      isAdmin,
+		imageType:    opts.ImageType,
+		arch:         arch,
+		dryRun:       opts.DryRun,
+		caCerts:      caCerts,
+		hooks:        hooks.NewRunner(cfg),
+		lifecycle:    lifecycle.Load(cfg.GetCatalogDir()),
+		policy:       pol,
 		distributors: distributors,
 	}, nil
 }
 
-// Run starts the interactive installation process
-func (i *Installer) Run() error {
+// CatalogDistributors returns every supported distributor, backed by its on-disk catalog
+func CatalogDistributors(cfg *config.Config) []*CachedDistributor {
+	catalogDir := cfg.GetCatalogDir()
+	return []*CachedDistributor{
+		NewCachedDistributor(NewAdoptiumDistributor(), catalogDir, cfg.GetCatalogTTL()),
+		// Future: NewAzulDistributor(), NewCorrettoDistributor()
+	}
+}
+
+// eolTag returns the menu tag for a feature release that has reached its end
+// of life, as the last tag column, or "" for a supported one
+func (i *Installer) eolTag(distributor Distributor, major string) string {
+	cycle, ok := i.lifecycle.Lookup(distributor.Name(), major)
+	if !ok || !cycle.IsEOL(time.Now()) {
+		return ""
+	}
+	return "  " + theme.ErrorStyle.Render("[EOL]")
+}
+
+// Run starts the interactive installation process. Cancelling ctx stops the
+// install without changing the configuration.
+func (i *Installer) Run(ctx context.Context) error {
 	// Styled header with JV theme
 	title := theme.Title.Padding(0, 2).Render("Java Installation Manager")
 	fmt.Println()
@@ -59,6 +135,11 @@ func (i *Installer) Run() error {
 		fmt.Println()
 	}
 
+	// Move JDKs installed by older versions of jv to the current layout
+	if !i.dryRun {
+		i.MigrateLegacyInstalls()
+	}
+
 	// Step 1: Select distributor
 	distributor, err := i.ShowDistributorMenu()
 	if err != nil {
@@ -71,18 +152,25 @@ func (i *Installer) Run() error {
 		return err
 	}
 
+	// Step 1.6: Select image type unless given on the command line
+	if i.imageType == "" {
+		if i.imageType, err = i.SelectImageType(); err != nil {
+			return err
+		}
+	}
+
 	if mode == "multi" {
-		return i.RunMultiInstall(distributor)
+		return i.RunMultiInstall(ctx, distributor)
 	}
 
 	// Single install (existing flow)
-	return i.RunSingleInstall(distributor)
+	return i.RunSingleInstall(ctx, distributor)
 }
 
 // RunSingleInstall handles single version installation
-func (i *Installer) RunSingleInstall(distributor Distributor) error {
+func (i *Installer) RunSingleInstall(ctx context.Context, distributor Distributor) error {
 	// Step 2: Select version
-	version, err := i.ShowVersionMenu(distributor)
+	version, err := i.ShowVersionMenu(ctx, distributor)
 	if err != nil {
 		return err
 	}
@@ -94,19 +182,82 @@ func (i *Installer) RunSingleInstall(distributor Distributor) error {
 	}
 
 	// Step 4: Install
-	installedPath, err := i.InstallVersion(distributor, version, scope)
-	if err != nil {
+	build, err := i.InstallVersion(ctx, distributor, version, scope)
+	if err != nil || i.dryRun {
 		return err
 	}
 
 	// Step 5: Configure and save
-	return i.finalizeInstallation([]string{installedPath}, []string{version}, scope, distributor.Name())
+	return i.finalizeInstallation(ctx, []*InstalledBuild{build}, []string{version}, scope, distributor.Name())
+}
+
+// RunNonInteractive installs the given version specs without prompting.
+// A spec is either a major version ("21", latest build) or a full version
+// ("17.0.8+7", "8u382-b05"). scope is "system" or "user".
+func (i *Installer) RunNonInteractive(ctx context.Context, specs []string, scope string) error {
+	if len(specs) == 0 {
+		return fmt.Errorf("no versions given")
+	}
+	for _, spec := range specs {
+		if !IsFullVersion(spec) && (spec == "" || MajorVersion(spec) != spec) {
+			return fmt.Errorf("invalid version %q: give a major version (21) or a full version (17.0.8+7, 21+35 or 8u382-b05)", spec)
+		}
+	}
+
+	if !i.dryRun {
+		i.MigrateLegacyInstalls()
+	}
+
+	if i.imageType == "" {
+		i.imageType = ImageJDK
+	}
+	distributor := i.distributors[1] // Adoptium for now
+	if len(specs) == 1 {
+		build, err := i.InstallVersion(ctx, distributor, specs[0], scope)
+		if err != nil || i.dryRun {
+			return err
+		}
+		return i.finalizeInstallation(ctx, []*InstalledBuild{build}, specs, scope, distributor.Name())
+	}
+
+	return i.installBatch(ctx, distributor, specs, scope)
+}
+
+// RunFromFile installs a JDK from a local archive without network access
+func (i *Installer) RunFromFile(ctx context.Context, archivePath string, checksum string, scope string) error {
+	isSystemWide := (scope == "system" && i.isAdmin)
+	if i.dryRun {
+		plan, err := PlanLocalInstall(archivePath, i.installOptions(isSystemWide))
+		if err != nil {
+			return err
+		}
+		fmt.Println()
+		printInstallPlan([]*InstallPlan{plan})
+		printDryRunNote()
+		return CheckDiskSpace([]*InstallPlan{plan})
+	}
+
+	i.MigrateLegacyInstalls()
+
+	fmt.Println()
+	fmt.Println(theme.Subtitle.Render(fmt.Sprintf("Installing Java from %s", filepath.Base(archivePath))))
+	fmt.Println()
+
+	local, err := InstallFromFile(ctx, archivePath, checksum, i.installOptions(isSystemWide))
+	if err != nil {
+		return fmt.Errorf("installation failed: %w", err)
+	}
+
+	// Record what the release file says rather than the command-line defaults
+	i.imageType = local.ImageType
+	i.arch = local.Arch
+	return i.finalizeInstallation(ctx, []*InstalledBuild{local}, []string{local.FullVersion}, scope, local.Vendor)
 }
 
 // RunMultiInstall handles multiple versions installation
-func (i *Installer) RunMultiInstall(distributor Distributor) error {
+func (i *Installer) RunMultiInstall(ctx context.Context, distributor Distributor) error {
 	// Step 2: Select multiple versions
-	versions, err := i.SelectMultipleVersions(distributor)
+	versions, err := i.SelectMultipleVersions(ctx, distributor)
 	if err != nil {
 		return err
 	}
@@ -121,54 +272,237 @@ func (i *Installer) RunMultiInstall(distributor Distributor) error {
 		return err
 	}
 
-	// Step 4: Install each version
-	fmt.Println()
-	fmt.Printf("Installing %d Java versions...\n", len(versions))
-	fmt.Println()
+	return i.installBatch(ctx, distributor, versions, scope)
+}
+
+// CancelledInstallError is returned when a batch install is cancelled after
+// some of its versions were installed. Those stay installed and are recorded
+// in the config; the post_install hooks and environment setup are skipped.
+type CancelledInstallError struct {
+	Installed []string // Versions installed before the install was cancelled
+	err       error
+}
+
+func (e *CancelledInstallError) Error() string {
+	return fmt.Sprintf("installation cancelled after installing Java %s", strings.Join(e.Installed, ", "))
+}
+
+func (e *CancelledInstallError) Unwrap() error {
+	return e.err
+}
+
+// installBatch downloads the given versions in parallel and installs them one by one.
+// A cancelled batch records the versions installed before the cancel, and
+// returns a CancelledInstallError then.
+func (i *Installer) installBatch(ctx context.Context, distributor Distributor, versions []string, scope string) error {
+	isSystemWide := (scope == "system" && i.isAdmin)
+	failures := make(map[string]error)
+
+	// Step 4: Resolve download information for every version
+	var labels []string
+	var pendingVersions []string
+	var infos []*DownloadInfo
+
+	spinnerErr := WithSpinner(ctx, "Fetching download information...", func(ctx context.Context) error {
+		for _, version := range versions {
+			info, err := resolveDownload(ctx, distributor, version, i.arch, i.imageType)
+			if ctx.Err() != nil {
+				return nil
+			}
+			if err != nil {
+				failures[version] = fmt.Errorf("failed to get download URL: %w", err)
+				continue
+			}
+			if err := i.policy.Check(distributor.Name(), info.Version); err != nil {
+				failures[version] = err
+				continue
+			}
+			labels = append(labels, "Java "+version)
+			pendingVersions = append(pendingVersions, version)
+			infos = append(infos, info)
+		}
+		return nil
+	})
+	if spinnerErr != nil {
+		return spinnerErr
+	}
+	printCatalogAge(distributor)
+
+	// Show what goes where, and stop before downloading if it cannot fit
+	plans := make([]*InstallPlan, 0, len(infos))
+	for idx, info := range infos {
+		plan, err := PlanInstall(info, MajorVersion(pendingVersions[idx]), distributor.Name(), i.installOptions(isSystemWide))
+		if err != nil {
+			return err
+		}
+		plans = append(plans, plan)
+	}
+	if len(plans) > 0 {
+		fmt.Println()
+		printInstallPlan(plans)
+	}
+	if i.dryRun {
+		printInstallFailures(versions, failures)
+		printDryRunNote()
+		if len(plans) == 0 {
+			return fmt.Errorf("none of the versions can be installed")
+		}
+		return CheckDiskSpace(plans)
+	}
+	if err := CheckDiskSpace(plans); err != nil {
+		return err
+	}
+
+	// Step 5: Download all archives in parallel
+	if len(infos) > 0 {
+		fmt.Println()
+		fmt.Printf("Downloading %d Java versions (up to %d at a time)...\n", len(infos), maxParallelDownloads)
+	}
+	downloadErrs := DownloadArchives(ctx, labels, infos, i.cache)
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 
-	installedPaths := []string{}
-	for idx, version := range versions {
-		fmt.Printf("[%d/%d] Installing Java %s...\n", idx+1, len(versions), version)
+	// Step 6: Verify and extract each downloaded version
+	builds := []*InstalledBuild{}
+	installedVersions := []string{}
+	for idx, version := range pendingVersions {
+		if downloadErrs[idx] != nil {
+			failures[version] = downloadErrs[idx]
+			continue
+		}
 
-		installedPath, err := i.InstallVersion(distributor, version, scope)
+		fmt.Println()
+		fmt.Println(theme.Subtitle.Render(fmt.Sprintf("[%d/%d] Installing Java %s", idx+1, len(pendingVersions), version)))
+
+		build, err := InstallJDK(ctx, infos[idx], MajorVersion(version), distributor.Name(), i.installOptions(isSystemWide))
+		if ctx.Err() != nil {
+			if err == nil {
+				// Cancelled after the JDK was moved into place
+				builds = append(builds, build)
+				installedVersions = append(installedVersions, version)
+			}
+			if len(builds) == 0 {
+				return ctx.Err()
+			}
+			// The versions installed before are in place; record them so that
+			// the configuration matches what is on disk
+			i.recordInstalls(builds, installedVersions, scope, distributor.Name())
+			return &CancelledInstallError{Installed: installedVersions, err: ctx.Err()}
+		}
 		if err != nil {
-			fmt.Printf("❌ Failed to install Java %s: %v\n", version, err)
+			failures[version] = fmt.Errorf("installation failed: %w", err)
 			continue
 		}
 
-		installedPaths = append(installedPaths, installedPath)
-		fmt.Printf("✓ Java %s installed successfully\n\n", version)
+		builds = append(builds, build)
+		installedVersions = append(installedVersions, version)
 	}
 
-	// Step 5: Configure and save
-	return i.finalizeInstallation(installedPaths, versions, scope, distributor.Name())
+	// Step 7: Configure, save and summarize
+	if len(builds) == 0 {
+		printInstallFailures(versions, failures)
+		return fmt.Errorf("no versions were installed")
+	}
+
+	if err := i.finalizeInstallation(ctx, builds, installedVersions, scope, distributor.Name()); err != nil {
+		return err
+	}
+	printInstallFailures(versions, failures)
+
+	return nil
 }
 
-// finalizeInstallation handles config saving and environment setup
-func (i *Installer) finalizeInstallation(paths []string, versions []string, scope string, distributorName string) error {
-	// Add to config
-	for idx, path := range paths {
-		if strings.EqualFold(scope, "user") {
-			i.config.AddCustomPath(path)
+// printCatalogAge tells the user when answers came from an outdated catalog
+// because the distributor could not be reached
+func printCatalogAge(distributor Distributor) {
+	cached, ok := distributor.(*CachedDistributor)
+	if !ok {
+		return
+	}
+	if asOf, stale := cached.Stale(); stale {
+		fmt.Println(theme.WarningMessage(fmt.Sprintf("%s is unreachable, using catalog as of %s",
+			distributor.Name(), asOf.Local().Format("2006-01-02 15:04"))))
+	}
+}
+
+// printInstallPlan shows what will be downloaded and installed where, and the
+// disk space this needs on each volume
+func printInstallPlan(plans []*InstallPlan) {
+	fmt.Println(theme.Subtitle.Render("Install plan"))
+	for _, plan := range plans {
+		if plan.Version != "" {
+			fmt.Printf("  %s %s\n", theme.CurrentStyle.Render("Java "+plan.Version),
+				theme.Faint.Render(fmt.Sprintf("(%s, %s, %s)", plan.Distributor, plan.ImageType, plan.Arch)))
 		}
 
-		installedJDK := config.InstalledJDK{
-			Version:     versions[idx],
-			Path:        path,
-			Distributor: distributorName,
-			InstalledAt: time.Now().Format(time.RFC3339),
-			Scope:       scope,
+		download := FormatSize(plan.DownloadSize) + " to download"
+		if plan.Cached {
+			download = "cached"
+		}
+		fmt.Printf("    %s %s %s\n", theme.LabelStyle.Render("Package:"), theme.ValueStyle.Render(plan.FileName),
+			theme.Faint.Render(fmt.Sprintf("(%s, %s)", FormatSize(plan.ArchiveSize), download)))
+
+		location := theme.PathStyle.Render(plan.InstallPath)
+		if plan.Version == "" {
+			location += theme.Faint.Render(" (directory named after the release file)")
+		}
+		fmt.Printf("    %s %s %s\n", theme.LabelStyle.Render("Install:"), location,
+			theme.Faint.Render("(~"+FormatSize(plan.InstallSize)+")"))
+		if plan.Replaces {
+			fmt.Println("    " + theme.WarningMessage("Replaces the existing installation"))
 		}
-		i.config.AddInstalledJDK(installedJDK)
 	}
 
-	if err := i.config.Save(); err != nil {
-		fmt.Printf("Warning: Failed to save config: %v\n", err)
+	for _, u := range PlanDiskUsage(plans) {
+		line := fmt.Sprintf("Disk space on %s: ~%s needed", u.Volume, FormatSize(u.Needed))
+		switch {
+		case !u.Known:
+			fmt.Println("  " + theme.InfoMessage(line+", free space unknown"))
+		case u.Enough():
+			fmt.Println("  " + theme.SuccessMessage(line+", "+FormatSize(u.Free)+" free"))
+		default:
+			fmt.Println("  " + theme.ErrorMessage(line+", only "+FormatSize(u.Free)+" free"))
+		}
+	}
+	fmt.Println()
+}
+
+// printDryRunNote confirms that a dry run changed nothing
+func printDryRunNote() {
+	fmt.Println(theme.InfoMessage("Dry run: nothing was downloaded, installed or configured"))
+}
+
+// printInstallFailures lists the versions of a batch install that failed, in selection order
+func printInstallFailures(versions []string, failures map[string]error) {
+	if len(failures) == 0 {
+		return
+	}
+
+	fmt.Println(theme.ErrorStyle.Render(fmt.Sprintf("Failed to install %d of %d versions:", len(failures), len(versions))))
+	for _, version := range versions {
+		if err, failed := failures[version]; failed {
+			fmt.Printf("  %s %s\n", theme.ErrorMessage("Java "+version+":"), err)
+		}
+	}
+	fmt.Println()
+}
+
+// finalizeInstallation records the builds in the config, runs the
+// post_install hooks and handles environment setup
+func (i *Installer) finalizeInstallation(ctx context.Context, builds []*InstalledBuild, versions []string, scope string, distributorName string) error {
+	i.recordInstalls(builds, versions, scope, distributorName)
+
+	// The JDKs stay installed when a fatal post_install hook fails
+	for idx, build := range builds {
+		if err := i.hooks.Run(ctx, hooks.PostInstall, i.hookTarget(build.Path, versions[idx], distributorName)); err != nil {
+			return err
+		}
 	}
 
 	// Configure environment for first installation if JAVA_HOME not set
-	if len(paths) > 0 {
-		if err := i.ConfigureEnvironment(paths[0]); err != nil {
+	if len(builds) > 0 && i.imageType.IsRuntime() {
+		if err := i.ConfigureEnvironment(ctx, builds[0].Path, versions[0], distributorName); err != nil {
 			fmt.Printf("\nNote: %v\n", err)
 		}
 	}
@@ -180,15 +514,15 @@ func (i *Installer) finalizeInstallation(paths []string, versions []string, scop
 	fmt.Println()
 
 	// Installation details
-	if len(paths) == 1 {
+	if len(builds) == 1 {
 		fmt.Println(theme.LabelStyle.Render(fmt.Sprintf("Java %s installed to:", versions[0])))
-		fmt.Printf("  %s\n", theme.PathStyle.Render(paths[0]))
+		fmt.Printf("  %s\n", theme.PathStyle.Render(builds[0].Path))
 	} else {
-		fmt.Println(theme.LabelStyle.Render(fmt.Sprintf("Installed %d Java versions:", len(paths))))
+		fmt.Println(theme.LabelStyle.Render(fmt.Sprintf("Installed %d Java versions:", len(builds))))
 		for idx, version := range versions {
 			fmt.Printf("  • %s → %s\n",
 				theme.SuccessStyle.Render("Java "+version),
-				theme.PathStyle.Render(paths[idx]))
+				theme.PathStyle.Render(builds[idx].Path))
 		}
 	}
 
@@ -203,6 +537,44 @@ func (i *Installer) finalizeInstallation(paths []string, versions []string, scop
 	return nil
 }
 
+// recordInstalls adds the builds and where they came from to the config and
+// saves it
+func (i *Installer) recordInstalls(builds []*InstalledBuild, versions []string, scope string, distributorName string) {
+	for idx, build := range builds {
+		path := build.Path
+		// Debug images, static libraries and sources cannot be used as JAVA_HOME
+		if i.imageType.IsRuntime() {
+			if strings.EqualFold(scope, "user") {
+				i.config.AddCustomPath(path)
+			} else {
+				// Make sure the vendor directory is scanned even if it isn't a standard location
+				i.config.AddSearchPath(filepath.Dir(path))
+			}
+		}
+
+		installedJDK := config.InstalledJDK{
+			Version:     MajorVersion(versions[idx]),
+			Path:        path,
+			Distributor: distributorName,
+			InstalledAt: time.Now().Format(time.RFC3339),
+			Scope:       scope,
+			ImageType:   string(i.imageType),
+			Arch:        i.arch,
+
+			FullVersion: build.FullVersion,
+			VendorBuild: build.VendorBuild,
+			SourceURL:   build.SourceURL,
+			Checksum:    build.Checksum,
+			Signature:   string(build.Signature),
+		}
+		i.config.AddInstalledJDK(installedJDK)
+	}
+
+	if err := i.config.Save(); err != nil {
+		fmt.Printf("Warning: Failed to save config: %v\n", err)
+	}
+}
+
 // SelectInstallScope asks user to choose installation scope (admin only)
 func (i *Installer) SelectInstallScope() (string, error) {
 	if !i.isAdmin {
@@ -254,16 +626,16 @@ func (i *Installer) ShowDistributorMenu() (Distributor, error) {
 }
 
 // ShowVersionMenu displays available versions and returns the selected one
-func (i *Installer) ShowVersionMenu(distributor Distributor) (string, error) {
+func (i *Installer) ShowVersionMenu(ctx context.Context, distributor Distributor) (string, error) {
 	var releases []JavaRelease
 	var fetchErr error
 
 	// Fetch with spinner
-	spinnerErr := WithSpinner(
+	spinnerErr := WithSpinner(ctx,
 		fmt.Sprintf("Fetching available versions from %s...", distributor.Name()),
-		func() error {
+		func(ctx context.Context) error {
 			var err error
-			releases, err = distributor.GetAvailableVersions()
+			releases, err = distributor.GetAvailableVersions(ctx)
 			fetchErr = err
 			return nil // Don't propagate error, just store it
 		},
@@ -272,6 +644,7 @@ func (i *Installer) ShowVersionMenu(distributor Distributor) (string, error) {
 	if spinnerErr != nil {
 		return "", spinnerErr
 	}
+	printCatalogAge(distributor)
 
 	if fetchErr != nil {
 		fmt.Printf("Warning: %v\n", fetchErr)
@@ -323,7 +696,7 @@ func (i *Installer) ShowVersionMenu(distributor Distributor) (string, error) {
 
 		left := theme.CurrentStyle.Render("Java") + " " + release.Version
 		// one space before tags, two spaces between tag columns
-		label := left + pad + " " + ltsCol + "  " + instCol
+		label := left + pad + " " + ltsCol + "  " + instCol + i.eolTag(distributor, release.Version)
 		option := huh.NewOption(label, release.Version)
 
 		if release.IsLTS {
@@ -348,20 +721,90 @@ func (i *Installer) ShowVersionMenu(distributor Distributor) (string, error) {
 		return "", err
 	}
 
+	// Step 2: pick a particular build of the selected major
+	return i.ShowBuildMenu(ctx, distributor, selected, installedVersions)
+}
+
+// ShowBuildMenu lets the user choose between the latest build of a major version
+// and an older patch/build. It returns the major itself for "latest".
+func (i *Installer) ShowBuildMenu(ctx context.Context, distributor Distributor, major string, installed []java.Version) (string, error) {
+	var builds []JavaBuild
+	var fetchErr error
+
+	spinnerErr := WithSpinner(ctx,
+		fmt.Sprintf("Fetching builds of Java %s...", major),
+		func(ctx context.Context) error {
+			var err error
+			builds, err = distributor.GetBuilds(ctx, major, i.arch, i.imageType)
+			fetchErr = err
+			return nil
+		},
+	)
+
+	if spinnerErr != nil {
+		return "", spinnerErr
+	}
+	printCatalogAge(distributor)
+
+	if fetchErr != nil || len(builds) == 0 {
+		if fetchErr != nil {
+			fmt.Printf("Warning: could not list builds, installing the latest: %v\n", fetchErr)
+		}
+		return major, nil
+	}
+
+	// Installed builds are matched on the version reported by java -version (no build number)
+	installedMap := make(map[string]bool)
+	for _, iv := range installed {
+		installedMap[iv.Version] = true
+	}
+
+	maxW := 0
+	for _, b := range builds {
+		if w := lipgloss.Width(b.Version); w > maxW {
+			maxW = w
+		}
+	}
+
+	options := []huh.Option[string]{
+		huh.NewOption(theme.CurrentStyle.Render("Latest")+fmt.Sprintf(" (%s)", builds[0].Version), major),
+	}
+	for _, build := range builds {
+		pad := strings.Repeat(" ", maxW-lipgloss.Width(build.Version))
+		label := build.Version + pad + "  " + theme.Faint.Render(build.ReleaseDate)
+		if base, _, _ := strings.Cut(build.Version, "+"); installedMap[base] {
+			label += "  " + theme.InfoStyle.Render("[Installed]")
+		}
+		options = append(options, huh.NewOption(label, build.Version))
+	}
+
+	var selected string
+	err := huh.NewSelect[string]().
+		Title(theme.Subtitle.Render(fmt.Sprintf("Select Java %s Build", major))).
+		Description(theme.Faint.Render("Pick an older build to reproduce a pinned environment")).
+		Options(options...).
+		Value(&selected).
+		Height(12).
+		Run()
+
+	if err != nil {
+		return "", err
+	}
+
 	return selected, nil
 }
 
 // SelectMultipleVersions allows installing multiple Java versions at once
-func (i *Installer) SelectMultipleVersions(distributor Distributor) ([]string, error) {
+func (i *Installer) SelectMultipleVersions(ctx context.Context, distributor Distributor) ([]string, error) {
 	var releases []JavaRelease
 	var fetchErr error
 
 	// Fetch with spinner
-	spinnerErr := WithSpinner(
+	spinnerErr := WithSpinner(ctx,
 		fmt.Sprintf("Fetching available versions from %s...", distributor.Name()),
-		func() error {
+		func(ctx context.Context) error {
 			var err error
-			releases, err = distributor.GetAvailableVersions()
+			releases, err = distributor.GetAvailableVersions(ctx)
 			fetchErr = err
 			return nil
 		},
@@ -370,6 +813,7 @@ func (i *Installer) SelectMultipleVersions(distributor Distributor) ([]string, e
 	if spinnerErr != nil {
 		return nil, spinnerErr
 	}
+	printCatalogAge(distributor)
 
 	if fetchErr != nil {
 		return nil, fetchErr
@@ -414,7 +858,7 @@ func (i *Installer) SelectMultipleVersions(distributor Distributor) ([]string, e
 		}
 
 		left := theme.CurrentStyle.Render("Java") + " " + release.Version
-		label := left + pad + " " + ltsCol + "  " + instCol
+		label := left + pad + " " + ltsCol + "  " + instCol + i.eolTag(distributor, release.Version)
 		options = append(options, huh.NewOption(label, release.Version))
 	}
 
@@ -435,6 +879,29 @@ func (i *Installer) SelectMultipleVersions(distributor Distributor) ([]string, e
 	return selected, nil
 }
 
+// SelectImageType asks which image of the build to install
+func (i *Installer) SelectImageType() (ImageType, error) {
+	var imageType ImageType
+
+	err := huh.NewSelect[ImageType]().
+		Title(theme.Subtitle.Render("Select Image Type")).
+		Options(
+			huh.NewOption(theme.CurrentStyle.Render("JDK")+" - full development kit (recommended)", ImageJDK),
+			huh.NewOption(theme.CurrentStyle.Render("JRE")+" - runtime only, for servers", ImageJRE),
+			huh.NewOption(theme.CurrentStyle.Render("Debug image")+" - debug symbols", ImageDebug),
+			huh.NewOption(theme.CurrentStyle.Render("Static libs")+" - for native image builds", ImageStaticLibs),
+			huh.NewOption(theme.CurrentStyle.Render("Sources")+" - JDK source code", ImageSources),
+		).
+		Value(&imageType).
+		Run()
+
+	if err != nil {
+		return "", err
+	}
+
+	return imageType, nil
+}
+
 // SelectInstallMode allows choosing between single and multi install
 func (i *Installer) SelectInstallMode() (string, error) {
 	var mode string
@@ -456,57 +923,190 @@ func (i *Installer) SelectInstallMode() (string, error) {
 }
 
 // InstallVersion downloads and installs the selected version
-func (i *Installer) InstallVersion(distributor Distributor, version string, scope string) (string, error) {
+func (i *Installer) InstallVersion(ctx context.Context, distributor Distributor, version string, scope string) (*InstalledBuild, error) {
 	// Installation header with JV theme
 	fmt.Println()
 	fmt.Println(theme.Subtitle.Render(fmt.Sprintf("Installing Java %s from %s", version, distributor.Name())))
 	fmt.Println()
 
-	// Get system architecture
-	arch := runtime.GOARCH
+	// Target architecture (the host's unless --arch was given)
+	arch := i.arch
+	if arch != java.HostArch() {
+		fmt.Println(theme.InfoMessage(fmt.Sprintf("Installing a %s build on a %s host", arch, java.HostArch())))
+	}
 
 	// Get download URL with spinner
 	var downloadInfo *DownloadInfo
 	var fetchErr error
 
-	spinnerErr := WithSpinner(
+	spinnerErr := WithSpinner(ctx,
 		"Fetching download information...",
-		func() error {
+		func(ctx context.Context) error {
 			var err error
-			downloadInfo, err = distributor.GetDownloadURL(version, arch)
+			downloadInfo, err = resolveDownload(ctx, distributor, version, arch, i.imageType)
 			fetchErr = err
 			return nil
 		},
 	)
 
 	if spinnerErr != nil {
-		return "", spinnerErr
+		return nil, spinnerErr
 	}
+	printCatalogAge(distributor)
 
 	if fetchErr != nil {
-		return "", fmt.Errorf("failed to get download URL: %w", fetchErr)
+		return nil, fmt.Errorf("failed to get download URL: %w", fetchErr)
+	}
+	if err := i.policy.Check(distributor.Name(), downloadInfo.Version); err != nil {
+		return nil, err
 	}
-
-	// Styled package info with JV theme
-	fmt.Printf("%s %s\n", theme.LabelStyle.Render("Package:"), theme.ValueStyle.Render(downloadInfo.FileName))
-	sizeMB := float64(downloadInfo.Size) / 1024 / 1024
-	fmt.Printf("%s %s\n", theme.LabelStyle.Render("Size:   "), theme.ValueStyle.Render(fmt.Sprintf("%.2f MB", sizeMB)))
-	fmt.Println()
 
 	// Determine isSystemWide based on scope
 	isSystemWide := (scope == "system" && i.isAdmin)
+	opts := i.installOptions(isSystemWide)
+
+	// Show what will be downloaded and installed where
+	plan, err := PlanInstall(downloadInfo, MajorVersion(version), distributor.Name(), opts)
+	if err != nil {
+		return nil, err
+	}
+	printInstallPlan([]*InstallPlan{plan})
+	if i.dryRun {
+		printDryRunNote()
+		return nil, CheckDiskSpace([]*InstallPlan{plan})
+	}
 
 	// Install JDK
-	installedPath, err := InstallJDK(downloadInfo, version, distributor.Name(), isSystemWide)
+	build, err := InstallJDK(ctx, downloadInfo, MajorVersion(version), distributor.Name(), opts)
 	if err != nil {
-		return "", fmt.Errorf("installation failed: %w", err)
+		return nil, fmt.Errorf("installation failed: %w", err)
+	}
+
+	return build, nil
+}
+
+// resolveDownload fetches download information for a major version (latest build)
+// or a full version spec (that particular build, or the newest build of a
+// release given without a build number)
+func resolveDownload(ctx context.Context, distributor Distributor, spec string, arch string, imageType ImageType) (*DownloadInfo, error) {
+	if IsFullVersion(spec) {
+		build, err := resolveBuild(ctx, distributor, spec, arch, imageType)
+		if err != nil {
+			return nil, err
+		}
+		return distributor.GetBuildDownloadURL(ctx, build, arch, imageType)
 	}
+	return distributor.GetDownloadURL(ctx, spec, arch, imageType)
+}
 
-	return installedPath, nil
+// installOptions returns the InstallJDK options for this run
+func (i *Installer) installOptions(isSystemWide bool) InstallOptions {
+	return InstallOptions{
+		SystemWide: isSystemWide,
+		Layout:     i.config.GetInstallLayout(),
+		Cache:      i.cache,
+		Verifier:   i.verifier,
+
+		CACerts:            i.caCerts,
+		TruststorePassword: i.config.GetTruststorePassword(),
+		Hooks:              i.hooks,
+		Policy:             i.policy,
+		ManifestDir:        i.config.GetManifestDir(),
+	}
 }
 
-// ConfigureEnvironment sets JAVA_HOME if not already set
-func (i *Installer) ConfigureEnvironment(jdkPath string) error {
+// MigrateLegacyInstalls moves JDKs that older versions of jv installed to
+// <root>\jdk-<major> into the configured install layout, updating the config,
+// custom paths and JAVA_HOME so that list and use keep working.
+// JDKs that cannot be moved (in use, or system-wide or the current JAVA_HOME
+// without admin rights) are left in place.
+func (i *Installer) MigrateLegacyInstalls() {
+	javaHome, _ := env.GetJavaHome()
+	moved := 0
+
+	for idx, jdk := range i.config.InstalledJDKs {
+		if !strings.EqualFold(filepath.Base(jdk.Path), "jdk-"+jdk.Version) {
+			continue
+		}
+		systemWide := strings.EqualFold(jdk.Scope, "system")
+		if systemWide && !i.isAdmin {
+			continue
+		}
+		// JAVA_HOME is a machine variable, so without admin rights it could not follow the move
+		isJavaHome := javaHome != "" && strings.EqualFold(filepath.Clean(javaHome), filepath.Clean(jdk.Path))
+		if isJavaHome && !i.isAdmin {
+			continue
+		}
+
+		release, err := java.ReadReleaseFile(jdk.Path)
+		if err != nil {
+			continue
+		}
+		arch := i.detector.GetArch(jdk.Path)
+		if arch == "" {
+			arch = java.HostArch()
+		}
+
+		root, err := InstallRoot(systemWide)
+		if err != nil {
+			continue
+		}
+		newPath, err := InstallDir(root, i.config.GetInstallLayout(), jdk.Distributor, releaseFullVersion(release), jdk.Version, arch, ImageJDK)
+		if err != nil || strings.EqualFold(newPath, jdk.Path) {
+			continue
+		}
+		if _, err := os.Stat(newPath); err == nil {
+			// The same build is already installed at the new location
+			continue
+		}
+
+		if err := os.MkdirAll(filepath.Dir(newPath), 0755); err == nil {
+			err = os.Rename(jdk.Path, newPath)
+		}
+		if err != nil {
+			fmt.Println(theme.WarningMessage(fmt.Sprintf("Could not move %s to the new layout: %v", jdk.Path, err)))
+			continue
+		}
+		if isJavaHome {
+			if err := env.SetJavaHome(newPath); err != nil {
+				// Never leave JAVA_HOME pointing at a directory that is gone
+				if os.Rename(newPath, jdk.Path) == nil {
+					fmt.Println(theme.WarningMessage(fmt.Sprintf("Left %s in place, JAVA_HOME could not be updated: %v", jdk.Path, err)))
+					continue
+				}
+				fmt.Println(theme.WarningMessage("JAVA_HOME still points to the old location, run: jv use " + jdk.Version))
+			}
+		}
+
+		if i.config.HasCustomPath(jdk.Path) {
+			i.config.RemoveCustomPath(jdk.Path)
+			i.config.AddCustomPath(newPath)
+		}
+		i.config.InstalledJDKs[idx].Path = newPath
+		moved++
+
+		fmt.Println(theme.InfoMessage(fmt.Sprintf("Moved Java %s to %s", jdk.Version, newPath)))
+	}
+
+	if moved > 0 {
+		if err := i.config.Save(); err != nil {
+			fmt.Printf("Warning: Failed to save config: %v\n", err)
+		}
+		fmt.Println()
+	}
+}
+
+// hookTarget describes an installed JDK to hooks, with the full version from
+// its release file when it has one
+func (i *Installer) hookTarget(path string, version string, distributorName string) hooks.Target {
+	if release, err := java.ReadReleaseFile(path); err == nil && releaseFullVersion(release) != "" {
+		version = releaseFullVersion(release)
+	}
+	return hooks.Target{Path: path, Version: version, Vendor: distributorName, Arch: i.arch}
+}
+
+// ConfigureEnvironment sets JAVA_HOME if not already set, running the switch hooks around the change
+func (i *Installer) ConfigureEnvironment(ctx context.Context, jdkPath string, version string, distributorName string) error {
 	// Check if JAVA_HOME is already set
 	currentJavaHome := os.Getenv("JAVA_HOME")
 	if currentJavaHome != "" {
@@ -532,6 +1132,11 @@ func (i *Installer) ConfigureEnvironment(jdkPath string) error {
 		return nil
 	}
 
+	target := i.hookTarget(jdkPath, version, distributorName)
+	if err := i.hooks.Run(ctx, hooks.PreSwitch, target); err != nil {
+		return err
+	}
+
 	// Set JAVA_HOME
 	fmt.Println()
 	fmt.Println(theme.InfoStyle.Render("Configuring JAVA_HOME..."))
@@ -543,5 +1148,5 @@ func (i *Installer) ConfigureEnvironment(jdkPath string) error {
 	fmt.Printf("  JAVA_HOME = %s\n", theme.PathStyle.Render(jdkPath))
 	fmt.Println(theme.Faint.Render("  Added %JAVA_HOME%\\bin to PATH"))
 
-	return nil
+	return i.hooks.Run(ctx, hooks.PostSwitch, target)
 }
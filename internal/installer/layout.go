@@ -0,0 +1,72 @@
+package installer
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"jv/internal/config"
+)
+
+// InstallDir expands an install_layout template below base. The template may use
+// {vendor}, {version} (full version, e.g. 21.0.5+11), {major}, {arch} and {image};
+// "/" and "\" both separate directories. Images other than the JDK get a
+// "-<image>" suffix unless the template places {image} itself.
+func InstallDir(base string, layout string, vendor string, version string, major string, arch string, imageType ImageType) (string, error) {
+	if strings.TrimSpace(layout) == "" {
+		layout = config.DefaultInstallLayout
+	}
+	if version == "" {
+		version = major
+	}
+	if imageType == "" {
+		imageType = ImageJDK
+	}
+	if imageType != ImageJDK && !strings.Contains(layout, "{image}") {
+		layout += "-{image}"
+	}
+
+	replacer := strings.NewReplacer(
+		"{vendor}", sanitizePathSegment(vendor),
+		"{version}", sanitizePathSegment(version),
+		"{major}", sanitizePathSegment(major),
+		"{arch}", sanitizePathSegment(arch),
+		"{image}", sanitizePathSegment(string(imageType)),
+	)
+	rel := filepath.Clean(filepath.FromSlash(strings.ReplaceAll(replacer.Replace(layout), "\\", "/")))
+
+	if !filepath.IsLocal(rel) {
+		return "", fmt.Errorf("install layout %q must stay inside %s", layout, base)
+	}
+	if strings.ContainsAny(rel, "{}") {
+		return "", fmt.Errorf("install layout %q has unknown placeholders", layout)
+	}
+
+	return filepath.Join(base, rel), nil
+}
+
+// sanitizePathSegment makes a value usable as a single directory name on Windows
+func sanitizePathSegment(value string) string {
+	value = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`<>:"/\|?*`, r) || r < 32 {
+			return '_'
+		}
+		return r
+	}, strings.TrimSpace(value))
+
+	value = strings.TrimRight(value, ". ")
+	if value == "" {
+		return "_"
+	}
+	return value
+}
+
+// releaseFullVersion returns the full version of a JDK from its release file,
+// e.g. "21.0.5+11", or "" if it cannot be determined
+func releaseFullVersion(release map[string]string) string {
+	version := release["JAVA_RUNTIME_VERSION"]
+	if version == "" {
+		version = release["JAVA_VERSION"]
+	}
+	return strings.TrimSuffix(version, "-LTS")
+}
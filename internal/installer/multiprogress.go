@@ -0,0 +1,232 @@
+package installer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/progress"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"jv/internal/theme"
+)
+
+type multiStartMsg struct {
+	index  int
+	total  int64
+	offset int64
+}
+
+type multiProgressMsg struct {
+	index int
+	progressMsg
+}
+
+type multiDoneMsg struct {
+	index int
+	err   error
+	note  string // shown instead of the bar when the download was skipped
+}
+
+// multiProgressEntry is the state of one download in MultiProgressModel
+type multiProgressEntry struct {
+	label      string
+	bar        progress.Model
+	total      int64
+	downloaded int64
+	resumed    int64 // bytes present before this run, excluded from the aggregate speed
+	started    bool
+	speed      string
+	note       string
+	err        error
+	done       bool
+}
+
+// MultiProgressModel renders one progress bar per download plus the aggregate speed
+type MultiProgressModel struct {
+	entries   []*multiProgressEntry
+	labelW    int
+	startTime time.Time
+	interrupt func() // called when the user presses ctrl+c
+}
+
+// NewMultiProgressModel creates a model with one bar per label
+func NewMultiProgressModel(labels []string) MultiProgressModel {
+	entries := make([]*multiProgressEntry, len(labels))
+	labelW := 0
+	for i, label := range labels {
+		entries[i] = &multiProgressEntry{
+			label: label,
+			bar: progress.New(
+				progress.WithDefaultGradient(),
+				progress.WithWidth(30),
+				progress.WithoutPercentage(),
+			),
+			speed: "0 B/s",
+		}
+		if w := lipgloss.Width(label); w > labelW {
+			labelW = w
+		}
+	}
+
+	return MultiProgressModel{
+		entries:   entries,
+		labelW:    labelW,
+		startTime: time.Now(),
+	}
+}
+
+func (m MultiProgressModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m MultiProgressModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		if msg.String() == "ctrl+c" {
+			if m.interrupt != nil {
+				m.interrupt()
+			}
+			return m, tea.Quit
+		}
+		return m, nil
+
+	case multiStartMsg:
+		e := m.entries[msg.index]
+		e.total = msg.total
+		e.downloaded = msg.offset
+		if !e.started {
+			e.resumed = msg.offset
+			e.started = true
+		}
+		return m, nil
+
+	case multiProgressMsg:
+		e := m.entries[msg.index]
+		e.downloaded = msg.downloaded
+		e.speed = msg.speed
+		return m, e.bar.SetPercent(msg.percent)
+
+	case multiDoneMsg:
+		e := m.entries[msg.index]
+		e.done = true
+		e.err = msg.err
+		e.note = msg.note
+		var cmd tea.Cmd
+		if msg.err == nil && msg.note == "" {
+			cmd = e.bar.SetPercent(1.0)
+		}
+		if m.allDone() {
+			return m, tea.Sequence(cmd, tea.Quit)
+		}
+		return m, cmd
+
+	case progress.FrameMsg:
+		// Each bar only reacts to its own frame messages
+		var cmds []tea.Cmd
+		for _, e := range m.entries {
+			bar, cmd := e.bar.Update(msg)
+			e.bar = bar.(progress.Model)
+			cmds = append(cmds, cmd)
+		}
+		return m, tea.Batch(cmds...)
+
+	default:
+		return m, nil
+	}
+}
+
+func (m MultiProgressModel) allDone() bool {
+	for _, e := range m.entries {
+		if !e.done {
+			return false
+		}
+	}
+	return true
+}
+
+func (m MultiProgressModel) View() string {
+	pad := strings.Repeat(" ", padding)
+
+	var b strings.Builder
+	b.WriteString("\n")
+
+	var downloaded, total, transferred int64
+	for _, e := range m.entries {
+		label := e.label + strings.Repeat(" ", m.labelW-lipgloss.Width(e.label))
+
+		var status string
+		switch {
+		case errors.Is(e.err, context.Canceled):
+			status = theme.WarningMessage("cancelled")
+		case e.err != nil:
+			status = theme.ErrorMessage("failed: " + e.err.Error())
+		case e.note != "":
+			status = theme.SuccessMessage(e.note)
+		case e.done:
+			status = e.bar.View() + "  " + theme.SuccessMessage(FormatSize(e.downloaded))
+		case !e.started:
+			status = theme.Faint.Render("waiting...")
+		default:
+			percent := 0.0
+			if e.total > 0 {
+				percent = float64(e.downloaded) / float64(e.total) * 100
+			}
+			status = e.bar.View() + "  " + helpStyle(fmt.Sprintf("%s / %s (%.0f%%) - %s",
+				FormatSize(e.downloaded), FormatSize(e.total), percent, e.speed))
+		}
+
+		b.WriteString(pad + theme.CurrentStyle.Render(label) + "  " + status + "\n")
+
+		if e.total > 0 {
+			total += e.total
+		}
+		downloaded += e.downloaded
+		if e.downloaded > e.resumed {
+			transferred += e.downloaded - e.resumed
+		}
+	}
+
+	speed := "0 B/s"
+	if elapsed := time.Since(m.startTime).Seconds(); elapsed > 0 {
+		speed = formatRate(float64(transferred) / elapsed)
+	}
+	b.WriteString("\n" + pad + helpStyle(fmt.Sprintf("Total: %s / %s - %s",
+		FormatSize(downloaded), FormatSize(total), speed)) + "\n")
+
+	return b.String()
+}
+
+// multiDownloadUI reports one download of a batch to a shared MultiProgressModel
+type multiDownloadUI struct {
+	program *tea.Program
+	index   int
+	label   string
+}
+
+func (u *multiDownloadUI) begin(total int64, offset int64) io.Writer {
+	u.program.Send(multiStartMsg{index: u.index, total: total, offset: offset})
+
+	w := newProgressWriter(total, func(msg progressMsg) {
+		u.program.Send(multiProgressMsg{index: u.index, progressMsg: msg})
+	})
+	w.reset(offset)
+	return w
+}
+
+func (u *multiDownloadUI) printf(format string, args ...interface{}) {
+	u.program.Send(tea.Printf(u.label+": "+format, args...)())
+}
+
+func (u *multiDownloadUI) finish(err error) {
+	u.program.Send(multiDoneMsg{index: u.index, err: err})
+}
+
+// skip marks the download as not needed, e.g. because the archive is already cached
+func (u *multiDownloadUI) skip(note string) {
+	u.program.Send(multiDoneMsg{index: u.index, note: note})
+}
@@ -0,0 +1,234 @@
+package installer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"jv/internal/hooks"
+	"jv/internal/java"
+)
+
+// implementorVendors maps IMPLEMENTOR values of release files to the vendor
+// names used for install directories
+var implementorVendors = map[string]string{
+	"eclipse adoptium":   "Eclipse Adoptium",
+	"adoptopenjdk":       "AdoptOpenJDK",
+	"azul systems, inc.": "Zulu",
+	"amazon.com inc.":    "Amazon Corretto",
+	"microsoft":          "Microsoft",
+	"bellsoft":           "BellSoft",
+	"oracle corporation": "Oracle",
+	"sap se":             "SapMachine",
+}
+
+// InstallFromFile installs a JDK from a local archive without network access.
+// The archive is checked against checksum ("sha256:<hex>" or a bare digest) when
+// given and against a detached signature next to it when one exists; version,
+// vendor, architecture and image type are read from the archive's release file.
+func InstallFromFile(ctx context.Context, archivePath string, checksum string, opts InstallOptions) (*InstalledBuild, error) {
+	if _, err := os.Stat(archivePath); err != nil {
+		return nil, fmt.Errorf("cannot read archive: %w", err)
+	}
+
+	var archiveChecksum string
+	if checksum != "" {
+		algo, digest, err := ParseChecksumSpec(checksum)
+		if err != nil {
+			return nil, err
+		}
+		archiveChecksum = formatChecksum(algo, digest)
+
+		var checksumErr error
+		spinnerErr := WithSpinner(ctx, "Verifying checksum...", func(context.Context) error {
+			checksumErr = VerifyChecksum(archivePath, algo, digest)
+			return nil
+		})
+		if spinnerErr != nil {
+			return nil, spinnerErr
+		}
+		if checksumErr != nil {
+			return nil, fmt.Errorf("checksum verification failed: %w", checksumErr)
+		}
+		fmt.Println("✓ Checksum verified successfully")
+	} else {
+		fmt.Println("⚠ No checksum given, the archive is not verified (use --checksum sha256:<hex>)")
+		// Still record what was installed
+		if digest, err := FileChecksum(archivePath, "sha256"); err == nil {
+			archiveChecksum = formatChecksum("sha256", digest)
+		}
+	}
+
+	// Nothing is extracted, let alone run, before the signature is checked
+	sigStatus, signer, sigErr := opts.Verifier.VerifyLocal(ctx, archivePath)
+	if sigErr != nil {
+		return nil, fmt.Errorf("signature verification failed: %w", sigErr)
+	}
+	if sigStatus == SignatureVerified {
+		fmt.Printf("✓ Signature verified successfully (%s)\n", signer)
+	}
+
+	plan, err := PlanLocalInstall(archivePath, opts)
+	if err != nil {
+		return nil, err
+	}
+	if err := CheckDiskSpace([]*InstallPlan{plan}); err != nil {
+		return nil, err
+	}
+
+	installRoot := plan.InstallPath
+	if err := os.MkdirAll(installRoot, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create installation directory: %w", err)
+	}
+
+	// The final directory depends on the release file, so stage at the install root
+	stagingDir, err := newStagingDir(installRoot)
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(stagingDir)
+
+	var extractedPath string
+	var extractErr error
+	spinnerErr := WithSpinner(ctx, "Extracting JDK...", func(ctx context.Context) error {
+		extractedPath, extractErr = ExtractArchive(ctx, archivePath, filepath.Base(archivePath), filepath.Join(stagingDir, "extract"))
+		return nil
+	})
+	if spinnerErr != nil {
+		return nil, spinnerErr
+	}
+	if extractErr != nil {
+		return nil, fmt.Errorf("extraction failed: %w", extractErr)
+	}
+	fmt.Println("✓ JDK extracted successfully")
+
+	// macOS archives keep the release file below Contents/Home
+	if home := filepath.Join(extractedPath, "Contents", "Home"); isDir(home) {
+		extractedPath = home
+	}
+
+	local, err := describeLocalJDK(extractedPath)
+	if err != nil {
+		return nil, err
+	}
+	vendor := local.Vendor
+	if vendor == "Unknown" {
+		vendor = "" // Reported as an unknown vendor rather than one called "Unknown"
+	}
+	if err := opts.Policy.Check(vendor, local.FullVersion); err != nil {
+		return nil, err
+	}
+
+	// A signature made by another distributor's key does not vouch for this vendor
+	if signer != "" && !strings.EqualFold(signer, local.Vendor) {
+		return nil, fmt.Errorf("signature verification failed: the archive is signed by %s but its release file names %s", signer, local.Vendor)
+	}
+
+	if extractedPath, err = jdkRoot(extractedPath, local.ImageType); err != nil {
+		return nil, err
+	}
+
+	if err := importCACerts(extractedPath, local.ImageType, opts); err != nil {
+		return nil, err
+	}
+	if err := verifyInstall(ctx, extractedPath, local.FullVersion, local.Arch, local.ImageType, filepath.Join(stagingDir, "smoke")); err != nil {
+		return nil, err
+	}
+
+	finalPath, err := InstallDir(installRoot, opts.Layout, local.Vendor, local.FullVersion, local.Version, local.Arch, local.ImageType)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(finalPath), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create installation directory: %w", err)
+	}
+	target := hooks.Target{Path: finalPath, Version: local.FullVersion, Vendor: local.Vendor, Arch: local.Arch}
+	if err := opts.Hooks.Run(ctx, hooks.PreInstall, target); err != nil {
+		return nil, err
+	}
+	if err := moveIntoPlace(ctx, extractedPath, finalPath); err != nil {
+		return nil, err
+	}
+
+	fmt.Printf("JDK installed successfully to: %s\n", finalPath)
+	recordManifest(ctx, finalPath, opts)
+	local.Path = finalPath
+	local.SourceURL = archivePath
+	if abs, err := filepath.Abs(archivePath); err == nil {
+		local.SourceURL = abs
+	}
+	local.Checksum = archiveChecksum
+	local.Signature = sigStatus
+	return local, nil
+}
+
+// PlanLocalInstall describes an install from a local archive. Version and final
+// directory are only known once the archive is extracted, so InstallPath is the
+// install root.
+func PlanLocalInstall(archivePath string, opts InstallOptions) (*InstallPlan, error) {
+	info, err := os.Stat(archivePath)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read archive: %w", err)
+	}
+
+	installRoot, err := InstallRoot(opts.SystemWide)
+	if err != nil {
+		return nil, err
+	}
+
+	return &InstallPlan{
+		FileName:    filepath.Base(archivePath),
+		ArchiveSize: info.Size(),
+		InstallSize: int64(float64(info.Size()) * extractionRatio),
+		InstallPath: installRoot,
+		Cached:      true,
+	}, nil
+}
+
+// describeLocalJDK infers vendor, version, architecture and image type of an
+// extracted JDK from its release file
+func describeLocalJDK(jdkPath string) (*InstalledBuild, error) {
+	release, err := java.ReadReleaseFile(jdkPath)
+	if err != nil {
+		return nil, fmt.Errorf("cannot identify archive: no release file found: %w", err)
+	}
+
+	fullVersion := releaseFullVersion(release)
+	if rest, ok := strings.CutPrefix(fullVersion, "1.8.0_"); ok {
+		// Java 8 reports 1.8.0_392; use the 8u392 form of release names
+		fullVersion = "8u" + rest
+	}
+	major := MajorVersion(fullVersion)
+	if major == "" {
+		return nil, fmt.Errorf("cannot identify archive: release file has no JAVA_VERSION")
+	}
+
+	vendor := strings.TrimSpace(release["IMPLEMENTOR"])
+	if mapped, ok := implementorVendors[strings.ToLower(vendor)]; ok {
+		vendor = mapped
+	}
+	if vendor == "" {
+		vendor = "Unknown"
+	}
+
+	arch, err := java.NormalizeArch(release["OS_ARCH"])
+	if err != nil {
+		arch = java.HostArch()
+	}
+
+	imageType := ImageJDK
+	if _, err := os.Stat(filepath.Join(jdkPath, "bin", "javac"+filepath.Ext(javaBinaryName()))); err != nil {
+		imageType = ImageJRE
+	}
+
+	return &InstalledBuild{
+		Vendor:      vendor,
+		VendorBuild: release["IMPLEMENTOR_VERSION"],
+		Version:     major,
+		FullVersion: fullVersion,
+		Arch:        arch,
+		ImageType:   imageType,
+	}, nil
+}
@@ -0,0 +1,119 @@
+package installer
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/x/term"
+)
+
+// ProgressMode selects how downloads and long-running steps report progress
+type ProgressMode string
+
+const (
+	ProgressAuto  ProgressMode = "auto"  // tty on a terminal, plain otherwise
+	ProgressTTY   ProgressMode = "tty"   // animated progress bars and spinners
+	ProgressPlain ProgressMode = "plain" // periodic log lines, for CI and redirected output
+	ProgressNone  ProgressMode = "none"  // no progress output at all
+)
+
+// plainProgressInterval is how often plain mode logs the state of a download
+const plainProgressInterval = 5 * time.Second
+
+// progressMode is the mode chosen on the command line
+var progressMode = ProgressAuto
+
+// ParseProgressMode validates a --progress value
+func ParseProgressMode(name string) (ProgressMode, error) {
+	switch mode := ProgressMode(strings.ToLower(strings.TrimSpace(name))); mode {
+	case ProgressAuto, ProgressTTY, ProgressPlain, ProgressNone:
+		return mode, nil
+	}
+	return "", fmt.Errorf("unknown progress mode %q (expected auto, tty, plain or none)", name)
+}
+
+// SetProgressMode sets how progress is reported for the rest of the run
+func SetProgressMode(mode ProgressMode) {
+	progressMode = mode
+}
+
+// currentProgressMode resolves ProgressAuto: Bubble Tea needs a terminal for both
+// input and output, anything else (CI logs, pipes, TERM=dumb) gets plain lines
+func currentProgressMode() ProgressMode {
+	if progressMode != ProgressAuto {
+		return progressMode
+	}
+	if os.Getenv("TERM") == "dumb" || !term.IsTerminal(os.Stdout.Fd()) || !term.IsTerminal(os.Stdin.Fd()) {
+		return ProgressPlain
+	}
+	return ProgressTTY
+}
+
+// singleDownloadUI renders one download and is closed once it is over
+type singleDownloadUI interface {
+	downloadUI
+	close()
+}
+
+// newDownloadUI returns the UI for a single download in the current progress
+// mode. cancel is called when the user interrupts the progress bar.
+func newDownloadUI(cancel func()) singleDownloadUI {
+	switch currentProgressMode() {
+	case ProgressTTY:
+		return &barUI{cancel: cancel}
+	case ProgressNone:
+		return &lineUI{quiet: true}
+	default:
+		return &lineUI{}
+	}
+}
+
+// lineUI reports a download as periodic log lines instead of a progress bar
+type lineUI struct {
+	label      string // prefix of every line, e.g. "Java 21: " in batch installs
+	quiet      bool   // only report retries
+	writer     *progressWriter
+	lastReport time.Time
+}
+
+func (u *lineUI) begin(total int64, offset int64) io.Writer {
+	if u.quiet {
+		return io.Discard
+	}
+
+	u.lastReport = time.Now()
+	u.writer = newProgressWriter(total, func(msg progressMsg) {
+		if time.Since(u.lastReport) < plainProgressInterval {
+			return
+		}
+		u.lastReport = time.Now()
+		fmt.Printf("%sdownloaded %s / %s (%.0f%%) at %s\n",
+			u.label, FormatSize(msg.downloaded), FormatSize(total), msg.percent*100, msg.speed)
+	})
+	u.writer.reset(offset)
+	return u.writer
+}
+
+func (u *lineUI) printf(format string, args ...interface{}) {
+	fmt.Printf(u.label+format+"\n", args...)
+}
+
+func (u *lineUI) finish(err error) {
+	// Failures are reported by the caller
+	if u.quiet || err != nil || u.writer == nil {
+		return
+	}
+	fmt.Printf("%sdownloaded %s at %s\n", u.label, FormatSize(u.writer.downloaded), u.writer.GetSpeed())
+}
+
+// skip reports a download of a batch that is not needed
+func (u *lineUI) skip(note string) {
+	if !u.quiet {
+		fmt.Printf("%s%s\n", u.label, note)
+	}
+}
+
+func (u *lineUI) close() {}
@@ -1,7 +1,10 @@
 package installer
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"strings"
 	"time"
 
@@ -97,6 +100,7 @@ type ProgressModel struct {
 	speed      string
 	err        error
 	done       bool
+	interrupt  func() // called when the user presses ctrl+c
 }
 
 func NewProgressModel(totalBytes int64) ProgressModel {
@@ -123,6 +127,9 @@ func (m ProgressModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
 		if msg.String() == "ctrl+c" {
+			if m.interrupt != nil {
+				m.interrupt()
+			}
 			return m, tea.Quit
 		}
 		return m, nil
@@ -163,6 +170,9 @@ func (m ProgressModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 }
 
 func (m ProgressModel) View() string {
+	if errors.Is(m.err, context.Canceled) {
+		return "Download cancelled\n"
+	}
 	if m.err != nil {
 		return "Error downloading: " + m.err.Error() + "\n"
 	}
@@ -192,51 +202,139 @@ func (m ProgressModel) View() string {
 		pad + helpStyle(info) + "\n"
 }
 
-// progressWriter is an io.Writer that sends progress updates to Bubble Tea
+// progressWriter is an io.Writer that reports download progress to a Bubble Tea program
 type progressWriter struct {
 	total      int64
 	downloaded int64
+	resumed    int64 // bytes already present when the current attempt started
 	startTime  time.Time
-	program    *tea.Program
+	report     func(progressMsg)
 }
 
-func newProgressWriter(total int64, program *tea.Program) *progressWriter {
+func newProgressWriter(total int64, report func(progressMsg)) *progressWriter {
 	return &progressWriter{
 		total:      total,
 		downloaded: 0,
 		startTime:  time.Now(),
-		program:    program,
+		report:     report,
 	}
 }
 
 func (pw *progressWriter) Write(p []byte) (int, error) {
 	n := len(p)
 	pw.downloaded += int64(n)
+	pw.send()
 
-	if pw.total > 0 && pw.program != nil {
+	return n, nil
+}
+
+// reset restarts tracking from offset, used when a download attempt is resumed
+func (pw *progressWriter) reset(offset int64) {
+	pw.downloaded = offset
+	pw.resumed = offset
+	pw.startTime = time.Now()
+	pw.send()
+}
+
+func (pw *progressWriter) send() {
+	if pw.total > 0 && pw.report != nil {
 		percent := float64(pw.downloaded) / float64(pw.total)
 		speed := pw.GetSpeed()
 
-		pw.program.Send(progressMsg{
+		pw.report(progressMsg{
 			percent:    percent,
 			downloaded: pw.downloaded,
 			speed:      speed,
 		})
 	}
-
-	return n, nil
 }
 
 func (pw *progressWriter) GetSpeed() string {
 	elapsed := time.Since(pw.startTime).Seconds()
 	if elapsed > 0 {
-		speed := float64(pw.downloaded) / elapsed
-		if speed >= 1024*1024 {
-			return fmt.Sprintf("%.2f MB/s", speed/(1024*1024))
-		} else if speed >= 1024 {
-			return fmt.Sprintf("%.2f KB/s", speed/1024)
-		}
-		return fmt.Sprintf("%.0f B/s", speed)
+		return formatRate(float64(pw.downloaded-pw.resumed) / elapsed)
 	}
 	return "0 B/s"
 }
+
+// formatRate formats a transfer rate in bytes per second
+func formatRate(speed float64) string {
+	if speed >= 1024*1024 {
+		return fmt.Sprintf("%.2f MB/s", speed/(1024*1024))
+	} else if speed >= 1024 {
+		return fmt.Sprintf("%.2f KB/s", speed/1024)
+	}
+	return fmt.Sprintf("%.0f B/s", speed)
+}
+
+// barUI renders a single download with ProgressModel
+type barUI struct {
+	program *tea.Program
+	writer  *progressWriter
+	done    chan struct{}
+	cancel  context.CancelFunc // cancels the download when the user presses ctrl+c
+}
+
+// begin starts the progress bar on the first attempt, or rewinds it on later attempts
+func (u *barUI) begin(total int64, offset int64) io.Writer {
+	if u.program == nil {
+		// Create progress model
+		model := NewProgressModel(total)
+		model.interrupt = u.cancel
+		u.program = tea.NewProgram(model)
+
+		// Create progress writer
+		program := u.program
+		u.writer = newProgressWriter(total, func(msg progressMsg) { program.Send(msg) })
+
+		// Start the progress UI in a goroutine
+		u.done = make(chan struct{})
+		go func() {
+			defer close(u.done)
+			if _, err := program.Run(); err != nil {
+				fmt.Printf("Error running progress: %v\n", err)
+			}
+		}()
+
+		// Give the UI a moment to start
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	u.writer.reset(offset)
+	return u.writer
+}
+
+func (u *barUI) printf(format string, args ...interface{}) {
+	if u.program != nil {
+		// Send (unlike Program.Printf) does not block once the UI has exited
+		u.program.Send(tea.Printf(format, args...)())
+		return
+	}
+	fmt.Printf(format+"\n", args...)
+}
+
+func (u *barUI) finish(err error) {
+	if u.program == nil {
+		return
+	}
+	if err != nil {
+		u.program.Send(progressErrMsg{err: err})
+		return
+	}
+
+	// Signal completion
+	u.program.Send(downloadCompleteMsg{})
+}
+
+// close waits for the progress UI to finish rendering
+func (u *barUI) close() {
+	if u.program == nil {
+		return
+	}
+	select {
+	case <-u.done:
+	case <-time.After(500 * time.Millisecond):
+		u.program.Quit()
+		<-u.done
+	}
+}
@@ -0,0 +1,136 @@
+package installer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"jv/internal/config"
+	"jv/internal/hooks"
+)
+
+// ReinstallFromArchive replaces a JDK jv installed with a fresh copy of the
+// archive it came from: the download cache entry with the checksum recorded at
+// install time or, for offline installs, the local archive if it is still
+// there. The archive must still match that checksum; its signature was checked
+// when the JDK was first installed.
+func ReinstallFromArchive(ctx context.Context, jdk config.InstalledJDK, opts InstallOptions) error {
+	version := jdk.FullVersion
+	if version == "" {
+		version = jdk.Version
+	}
+	if jdk.Checksum == "" {
+		return fmt.Errorf("no archive checksum recorded for %s, it was installed by an older version of jv; reinstall it with 'jv install %s'", jdk.Path, version)
+	}
+	algo, digest, err := ParseChecksumSpec(jdk.Checksum)
+	if err != nil {
+		return err
+	}
+
+	var archivePath, fileName string
+	entry, cached := opts.Cache.Lookup(algo, digest)
+	switch {
+	case cached:
+		archivePath, fileName = entry.Path, entry.FileName
+	case isLocalArchive(jdk.SourceURL):
+		archivePath, fileName = jdk.SourceURL, filepath.Base(jdk.SourceURL)
+	default:
+		return fmt.Errorf("the archive of Java %s is no longer in the download cache; reinstall it with 'jv install %s'", version, version)
+	}
+
+	var checksumErr error
+	spinnerErr := WithSpinner(ctx, "Verifying checksum...", func(context.Context) error {
+		checksumErr = VerifyChecksum(archivePath, algo, digest)
+		return nil
+	})
+	if spinnerErr != nil {
+		return spinnerErr
+	}
+	if checksumErr != nil {
+		if cached {
+			opts.Cache.Remove(entry)
+		}
+		return fmt.Errorf("checksum verification failed: %w", checksumErr)
+	}
+	fmt.Println("✓ Checksum verified successfully")
+
+	// Stage next to the installation so that it can be swapped in atomically
+	stagingDir, err := newStagingDir(filepath.Dir(jdk.Path))
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(stagingDir)
+
+	var extractedPath string
+	var extractErr error
+	spinnerErr = WithSpinner(ctx, "Extracting JDK...", func(ctx context.Context) error {
+		extractedPath, extractErr = ExtractArchive(ctx, archivePath, fileName, filepath.Join(stagingDir, "extract"))
+		return nil
+	})
+	if spinnerErr != nil {
+		return spinnerErr
+	}
+	if extractErr != nil {
+		return fmt.Errorf("extraction failed: %w", extractErr)
+	}
+	fmt.Println("✓ JDK extracted successfully")
+
+	imageType := ImageType(jdk.ImageType)
+	if extractedPath, err = jdkRoot(extractedPath, imageType); err != nil {
+		return err
+	}
+	if err := importCACerts(extractedPath, imageType, opts); err != nil {
+		return err
+	}
+	if err := verifyInstall(ctx, extractedPath, jdk.FullVersion, jdk.Arch, imageType, filepath.Join(stagingDir, "smoke")); err != nil {
+		return err
+	}
+
+	target := hooks.Target{Path: jdk.Path, Version: version, Vendor: jdk.Distributor, Arch: jdk.Arch}
+	if err := opts.Hooks.Run(ctx, hooks.PreInstall, target); err != nil {
+		return err
+	}
+	if err := moveIntoPlace(ctx, extractedPath, jdk.Path); err != nil {
+		return err
+	}
+	if cached {
+		opts.Cache.Touch(entry)
+	}
+
+	fmt.Printf("JDK reinstalled successfully to: %s\n", jdk.Path)
+	recordManifest(ctx, jdk.Path, opts)
+	return nil
+}
+
+// Reinstall restores the JDK jv installed at path from its archive, e.g. after
+// jv verify found files that are missing or modified. The install hooks and
+// the policy apply as they do to a new install.
+func (i *Installer) Reinstall(ctx context.Context, path string) error {
+	jdk := i.config.GetInstalledJDK(path)
+	if jdk == nil {
+		return fmt.Errorf("%s was not installed by jv", path)
+	}
+	version := jdk.FullVersion
+	if version == "" {
+		version = jdk.Version
+	}
+	if err := i.policy.Check(jdk.Distributor, version); err != nil {
+		return err
+	}
+
+	if err := ReinstallFromArchive(ctx, *jdk, i.installOptions(jdk.Scope == "system")); err != nil {
+		return err
+	}
+	return i.hooks.Run(ctx, hooks.PostInstall, hooks.Target{Path: jdk.Path, Version: version, Vendor: jdk.Distributor, Arch: jdk.Arch})
+}
+
+// isLocalArchive reports whether source names an archive file that still exists
+func isLocalArchive(source string) bool {
+	if source == "" || strings.HasPrefix(source, "https://") || strings.HasPrefix(source, "http://") {
+		return false
+	}
+	info, err := os.Stat(source)
+	return err == nil && info.Mode().IsRegular()
+}
@@ -0,0 +1,344 @@
+package installer
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+)
+
+// adoptiumKeyFingerprint is the OpenPGP key Eclipse Adoptium signs its binaries with
+// (see https://adoptium.net/docs/verify-binaries)
+const adoptiumKeyFingerprint = "3B04D753C9050D9A5D343F39843C48A565F8F04B"
+
+// pinnedKeyFingerprints maps distributor names to the fingerprints of their signing keys.
+// The keys are built into jv from keys/*.asc; a key missing there is fetched from
+// keyServerURL once and kept in the cache. Either way it is only trusted if its
+// fingerprint matches. signature_keys in jv.json replaces these with local key files.
+var pinnedKeyFingerprints = map[string][]string{
+	"Eclipse Adoptium": {adoptiumKeyFingerprint},
+}
+
+//go:embed keys
+var embeddedKeys embed.FS
+
+var keyServerURL = "https://keyserver.ubuntu.com/pks/lookup?op=get&options=mr&search=0x%s"
+
+// errNoKnownKey means jv has no key at all for a distributor, as opposed to a
+// known key that could not be loaded
+var errNoKnownKey = errors.New("no signing key is known")
+
+// errKeyUnavailable means a pinned key is neither built in nor cached and the
+// key server cannot be reached, e.g. behind a proxy or on an offline machine
+var errKeyUnavailable = errors.New("signing key is not available")
+
+// SignatureStatus describes the outcome of signature verification for an archive
+type SignatureStatus string
+
+const (
+	SignatureVerified SignatureStatus = "verified" // valid signature from a trusted key
+	SignatureUnsigned SignatureStatus = "unsigned" // the distributor published no signature
+	SignatureNoKey    SignatureStatus = "no-key"   // no trusted key was available to check it
+)
+
+// SignatureVerifier checks detached OpenPGP signatures against pinned or configured keys
+type SignatureVerifier struct {
+	keyDir    string              // where fetched pinned keys are kept
+	overrides map[string][]string // distributor -> armored key files from the config
+	require   bool
+	keyrings  map[string]openpgp.EntityList
+}
+
+// NewSignatureVerifier creates a verifier. keyDir caches keys fetched for pinned
+// fingerprints, overrides comes from signature_keys in the config, and require
+// refuses any archive that cannot be verified.
+func NewSignatureVerifier(keyDir string, overrides map[string][]string, require bool) *SignatureVerifier {
+	return &SignatureVerifier{
+		keyDir:    keyDir,
+		overrides: overrides,
+		require:   require,
+		keyrings:  make(map[string]openpgp.EntityList),
+	}
+}
+
+// Required reports whether unsigned or unverifiable archives are refused
+func (v *SignatureVerifier) Required() bool {
+	return v.require
+}
+
+// Verify checks archivePath against the detached signature published at signatureURL.
+// A bad signature or a key that does not match its pinned fingerprint is always
+// an error; a missing signature, a distributor jv has no key for, or a pinned
+// key that cannot be fetched is an error only when signatures are required.
+func (v *SignatureVerifier) Verify(ctx context.Context, distributor string, archivePath string, signatureURL string) (SignatureStatus, error) {
+	if signatureURL == "" {
+		if v.require {
+			return SignatureUnsigned, fmt.Errorf("refusing to install unsigned archive: %s publishes no signature for %s", distributor, filepath.Base(archivePath))
+		}
+		return SignatureUnsigned, nil
+	}
+
+	// A missing key is reported as unchecked; a key that fails its pinned
+	// fingerprint must not quietly turn verification off
+	keyring, err := v.keyring(ctx, distributor)
+	if (errors.Is(err, errNoKnownKey) || errors.Is(err, errKeyUnavailable)) && !v.require {
+		return SignatureNoKey, nil
+	}
+	if err != nil {
+		return SignatureNoKey, fmt.Errorf("cannot verify signature: %w", err)
+	}
+
+	sigPath := archivePath + ".sig"
+	if _, err := os.Stat(sigPath); err != nil {
+		if err := fetchSmallFile(ctx, signatureURL, sigPath); err != nil {
+			if v.require {
+				return SignatureUnsigned, fmt.Errorf("failed to download signature: %w", err)
+			}
+			return SignatureUnsigned, nil
+		}
+	}
+
+	if err := checkSignature(keyring, archivePath, sigPath); err != nil {
+		os.Remove(sigPath)
+		return "", fmt.Errorf("invalid signature for %s: %w", filepath.Base(archivePath), err)
+	}
+
+	return SignatureVerified, nil
+}
+
+// VerifyLocal checks a local archive against a detached signature stored next to
+// it (<archive>.sig or <archive>.asc), before anything is extracted from it. The
+// signer is found by trying the keys of every distributor jv knows, so it never
+// depends on the archive's own contents, and is returned with the status. A
+// signature that no trusted key verifies is an error. Unlike Verify it never
+// downloads or removes files.
+func (v *SignatureVerifier) VerifyLocal(ctx context.Context, archivePath string) (SignatureStatus, string, error) {
+	sigPath := ""
+	for _, ext := range []string{".sig", ".asc"} {
+		if _, err := os.Stat(archivePath + ext); err == nil {
+			sigPath = archivePath + ext
+			break
+		}
+	}
+	if sigPath == "" {
+		if v.require {
+			return SignatureUnsigned, "", fmt.Errorf("refusing to install unsigned archive: no %s.sig found", filepath.Base(archivePath))
+		}
+		return SignatureUnsigned, "", nil
+	}
+
+	var keyErrs []error
+	for _, distributor := range v.distributors() {
+		keyring, err := v.keyring(ctx, distributor)
+		if err != nil {
+			keyErrs = append(keyErrs, err)
+			continue
+		}
+		if err := checkSignature(keyring, archivePath, sigPath); err == nil {
+			return SignatureVerified, distributor, nil
+		}
+	}
+
+	err := fmt.Errorf("invalid signature for %s: %s is not from a trusted key", filepath.Base(archivePath), filepath.Base(sigPath))
+	return "", "", errors.Join(append([]error{err}, keyErrs...)...)
+}
+
+// distributors returns the distributors with pinned or configured signing keys
+func (v *SignatureVerifier) distributors() []string {
+	var names []string
+	for name := range pinnedKeyFingerprints {
+		names = append(names, name)
+	}
+	for name, files := range v.overrides {
+		if _, pinned := pinnedKeyFingerprints[name]; !pinned && len(files) > 0 {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// keyring returns the trusted keys for a distributor
+func (v *SignatureVerifier) keyring(ctx context.Context, distributor string) (openpgp.EntityList, error) {
+	if keyring, ok := v.keyrings[distributor]; ok {
+		return keyring, nil
+	}
+
+	var keyring openpgp.EntityList
+	if files, ok := v.overrides[distributor]; ok && len(files) > 0 {
+		// Keys configured by the user are trusted as-is
+		for _, file := range files {
+			entities, err := readKeyFile(file)
+			if err != nil {
+				return nil, fmt.Errorf("failed to load signature key %s: %w", file, err)
+			}
+			keyring = append(keyring, entities...)
+		}
+	} else {
+		fingerprints := pinnedKeyFingerprints[distributor]
+		if len(fingerprints) == 0 {
+			return nil, fmt.Errorf("%w for %s", errNoKnownKey, distributor)
+		}
+		for _, fpr := range fingerprints {
+			entity, err := v.pinnedKey(ctx, fpr)
+			if err != nil {
+				return nil, err
+			}
+			keyring = append(keyring, entity)
+		}
+	}
+
+	v.keyrings[distributor] = keyring
+	return keyring, nil
+}
+
+// pinnedKey returns the key with the given fingerprint: the one built into jv,
+// or else a copy in keyDir fetched from the key server before, or else a fresh
+// fetch. A key is rejected unless its fingerprint matches.
+func (v *SignatureVerifier) pinnedKey(ctx context.Context, fingerprint string) (*openpgp.Entity, error) {
+	if entity, err := embeddedKey(fingerprint); entity != nil || err != nil {
+		return entity, err
+	}
+
+	keyPath := filepath.Join(v.keyDir, fingerprint+".asc")
+	data, err := os.ReadFile(keyPath)
+	if err != nil {
+		data, err = fetchKey(ctx, fingerprint)
+		if err != nil {
+			return nil, fmt.Errorf("%w: failed to fetch %s: %v", errKeyUnavailable, fingerprint, err)
+		}
+	}
+
+	entity, err := matchKey(data, fingerprint)
+	if err != nil {
+		os.Remove(keyPath)
+		return nil, err
+	}
+
+	// Remember the key so later installs work offline
+	if err := os.MkdirAll(v.keyDir, 0755); err == nil {
+		os.WriteFile(keyPath, data, 0644)
+	}
+	return entity, nil
+}
+
+// embeddedKey returns the built-in key with the given fingerprint, or nil
+// without an error when none of the keys/*.asc files holds it
+func embeddedKey(fingerprint string) (*openpgp.Entity, error) {
+	files, _ := fs.Glob(embeddedKeys, "keys/*.asc")
+	for _, file := range files {
+		data, err := embeddedKeys.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		if entity, err := matchKey(data, fingerprint); err == nil {
+			return entity, nil
+		}
+	}
+	return nil, nil
+}
+
+// matchKey parses an armored key ring and returns the key with fingerprint
+func matchKey(data []byte, fingerprint string) (*openpgp.Entity, error) {
+	entities, err := openpgp.ReadArmoredKeyRing(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse signing key %s: %w", fingerprint, err)
+	}
+	for _, entity := range entities {
+		if strings.EqualFold(hex.EncodeToString(entity.PrimaryKey.Fingerprint), fingerprint) {
+			return entity, nil
+		}
+	}
+	return nil, fmt.Errorf("signing key does not match pinned fingerprint %s", fingerprint)
+}
+
+// checkSignature verifies a detached signature, accepting binary and ASCII-armored files
+func checkSignature(keyring openpgp.EntityList, archivePath string, sigPath string) error {
+	archive, err := os.Open(archivePath)
+	if err != nil {
+		return err
+	}
+	defer archive.Close()
+
+	sig, err := os.ReadFile(sigPath)
+	if err != nil {
+		return err
+	}
+
+	if bytes.HasPrefix(bytes.TrimSpace(sig), []byte("-----BEGIN PGP SIGNATURE")) {
+		_, err = openpgp.CheckArmoredDetachedSignature(keyring, archive, bytes.NewReader(sig), nil)
+	} else {
+		_, err = openpgp.CheckDetachedSignature(keyring, archive, bytes.NewReader(sig), nil)
+	}
+	return err
+}
+
+// readKeyFile reads an armored or binary OpenPGP public key file
+func readKeyFile(path string) (openpgp.EntityList, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if bytes.Contains(data, []byte("-----BEGIN PGP")) {
+		return openpgp.ReadArmoredKeyRing(bytes.NewReader(data))
+	}
+	return openpgp.ReadKeyRing(bytes.NewReader(data))
+}
+
+// fetchKey downloads an armored public key from the key server
+func fetchKey(ctx context.Context, fingerprint string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf(keyServerURL, fingerprint), nil)
+	if err != nil {
+		return nil, err
+	}
+	client := &http.Client{Timeout: 15 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("key server returned status %d", resp.StatusCode)
+	}
+
+	return io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+}
+
+// fetchSmallFile downloads a small file such as a signature without progress output
+func fetchSmallFile(ctx context.Context, url string, destPath string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("server returned status %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(destPath, data, 0644)
+}
@@ -0,0 +1,263 @@
+package installer
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+)
+
+// newTestKey generates a signing key and writes its armored public key to dir
+func newTestKey(t *testing.T, dir string, name string) (*openpgp.Entity, string) {
+	t.Helper()
+	entity, err := openpgp.NewEntity(name, "", strings.ToLower(name)+"@example.com", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	w, err := armor.Encode(&buf, openpgp.PublicKeyType, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := entity.Serialize(w); err != nil {
+		t.Fatal(err)
+	}
+	w.Close()
+
+	keyPath := filepath.Join(dir, name+".asc")
+	if err := os.WriteFile(keyPath, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return entity, keyPath
+}
+
+// fingerprint returns the fingerprint of entity as pinned in pinnedKeyFingerprints
+func fingerprint(entity *openpgp.Entity) string {
+	return strings.ToUpper(hex.EncodeToString(entity.PrimaryKey.Fingerprint))
+}
+
+// writeSignedArchive writes an archive and a detached signature next to it
+// (<archive>.sig, or an armored <archive>.asc)
+func writeSignedArchive(t *testing.T, dir string, signer *openpgp.Entity, armored bool) string {
+	t.Helper()
+	archivePath := filepath.Join(dir, "jdk.zip")
+	content := []byte("archive content")
+	if err := os.WriteFile(archivePath, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var sig bytes.Buffer
+	sigPath := archivePath + ".sig"
+	var err error
+	if armored {
+		sigPath = archivePath + ".asc"
+		err = openpgp.ArmoredDetachSign(&sig, signer, bytes.NewReader(content), nil)
+	} else {
+		err = openpgp.DetachSign(&sig, signer, bytes.NewReader(content), nil)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(sigPath, sig.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return archivePath
+}
+
+// isolatePinnedKeys replaces the pinned keys for the duration of the test, so
+// that no test reaches out to the key server for a real distributor's key
+func isolatePinnedKeys(t *testing.T, pins map[string][]string) {
+	t.Helper()
+	saved := pinnedKeyFingerprints
+	pinnedKeyFingerprints = pins
+	t.Cleanup(func() { pinnedKeyFingerprints = saved })
+}
+
+func TestVerifyLocalGoodSignature(t *testing.T) {
+	isolatePinnedKeys(t, nil)
+	for _, armored := range []bool{false, true} {
+		dir := t.TempDir()
+		vendor, keyPath := newTestKey(t, dir, "Vendor")
+		_, otherKeyPath := newTestKey(t, dir, "Other")
+		archivePath := writeSignedArchive(t, dir, vendor, armored)
+
+		verifier := NewSignatureVerifier(t.TempDir(), map[string][]string{
+			"Other Vendor": {otherKeyPath},
+			"Test Vendor":  {keyPath},
+		}, true)
+		status, signer, err := verifier.VerifyLocal(context.Background(), archivePath)
+		if err != nil {
+			t.Fatalf("armored=%v: %v", armored, err)
+		}
+		if status != SignatureVerified || signer != "Test Vendor" {
+			t.Errorf("armored=%v: got %q signed by %q, want verified by Test Vendor", armored, status, signer)
+		}
+	}
+}
+
+func TestVerifyLocalBadSignature(t *testing.T) {
+	isolatePinnedKeys(t, nil)
+	dir := t.TempDir()
+	vendor, keyPath := newTestKey(t, dir, "Vendor")
+	archivePath := writeSignedArchive(t, dir, vendor, false)
+	if err := os.WriteFile(archivePath, []byte("tampered content"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	verifier := NewSignatureVerifier(t.TempDir(), map[string][]string{"Test Vendor": {keyPath}}, false)
+	if _, _, err := verifier.VerifyLocal(context.Background(), archivePath); err == nil {
+		t.Fatal("expected a tampered archive to fail verification")
+	}
+}
+
+func TestVerifyLocalUntrustedKey(t *testing.T) {
+	isolatePinnedKeys(t, nil)
+	dir := t.TempDir()
+	stranger, _ := newTestKey(t, dir, "Stranger")
+	_, keyPath := newTestKey(t, dir, "Vendor")
+	archivePath := writeSignedArchive(t, dir, stranger, false)
+
+	// Signatures must not pass as "no key" when signatures are optional
+	verifier := NewSignatureVerifier(t.TempDir(), map[string][]string{"Test Vendor": {keyPath}}, false)
+	if _, _, err := verifier.VerifyLocal(context.Background(), archivePath); err == nil {
+		t.Fatal("expected a signature from an untrusted key to fail verification")
+	}
+}
+
+func TestVerifyLocalMissingSignature(t *testing.T) {
+	isolatePinnedKeys(t, nil)
+	archivePath := filepath.Join(t.TempDir(), "jdk.zip")
+	if err := os.WriteFile(archivePath, []byte("archive content"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	status, _, err := NewSignatureVerifier(t.TempDir(), nil, false).VerifyLocal(context.Background(), archivePath)
+	if err != nil || status != SignatureUnsigned {
+		t.Errorf("got %q, %v; want unsigned without an error", status, err)
+	}
+	if _, _, err := NewSignatureVerifier(t.TempDir(), nil, true).VerifyLocal(context.Background(), archivePath); err == nil {
+		t.Error("expected an unsigned archive to be refused when signatures are required")
+	}
+}
+
+func TestVerifyPinnedKey(t *testing.T) {
+	dir := t.TempDir()
+	vendor, keyPath := newTestKey(t, dir, "Vendor")
+	archivePath := writeSignedArchive(t, dir, vendor, false)
+	isolatePinnedKeys(t, map[string][]string{"Pinned Vendor": {fingerprint(vendor)}})
+
+	// A key fetched before is kept in keyDir under its fingerprint
+	keyDir := t.TempDir()
+	data, _ := os.ReadFile(keyPath)
+	os.WriteFile(filepath.Join(keyDir, fingerprint(vendor)+".asc"), data, 0644)
+
+	// The signature is already next to the archive, so nothing is downloaded
+	status, err := NewSignatureVerifier(keyDir, nil, true).Verify(context.Background(), "Pinned Vendor", archivePath, "https://example.invalid/jdk.zip.sig")
+	if err != nil || status != SignatureVerified {
+		t.Fatalf("got %q, %v; want verified", status, err)
+	}
+}
+
+func TestVerifyPinnedFingerprintMismatch(t *testing.T) {
+	dir := t.TempDir()
+	vendor, _ := newTestKey(t, dir, "Vendor")
+	_, impostorPath := newTestKey(t, dir, "Impostor")
+	archivePath := writeSignedArchive(t, dir, vendor, false)
+	isolatePinnedKeys(t, map[string][]string{"Pinned Vendor": {fingerprint(vendor)}})
+
+	// A different key stored under the pinned fingerprint must be rejected
+	keyDir := t.TempDir()
+	cachedKey := filepath.Join(keyDir, fingerprint(vendor)+".asc")
+	data, _ := os.ReadFile(impostorPath)
+	os.WriteFile(cachedKey, data, 0644)
+
+	// A key that cannot be loaded is an error even when signatures are optional
+	_, err := NewSignatureVerifier(keyDir, nil, false).Verify(context.Background(), "Pinned Vendor", archivePath, "https://example.invalid/jdk.zip.sig")
+	if err == nil || !strings.Contains(err.Error(), "does not match pinned fingerprint") {
+		t.Fatalf("got %v, want a fingerprint mismatch", err)
+	}
+	if _, err := os.Stat(cachedKey); !os.IsNotExist(err) {
+		t.Error("the mismatching key was left in the key cache")
+	}
+}
+
+func TestVerifyUnavailableKey(t *testing.T) {
+	dir := t.TempDir()
+	vendor, _ := newTestKey(t, dir, "Vendor")
+	archivePath := writeSignedArchive(t, dir, vendor, false)
+	isolatePinnedKeys(t, map[string][]string{"Pinned Vendor": {fingerprint(vendor)}})
+
+	// The key is not cached and the key server cannot deliver it
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+	saved := keyServerURL
+	keyServerURL = server.URL + "/%s"
+	t.Cleanup(func() { keyServerURL = saved })
+
+	status, err := NewSignatureVerifier(t.TempDir(), nil, false).Verify(context.Background(), "Pinned Vendor", archivePath, "https://example.invalid/jdk.zip.sig")
+	if err != nil || status != SignatureNoKey {
+		t.Errorf("got %q, %v; want no-key without an error", status, err)
+	}
+	if _, err := NewSignatureVerifier(t.TempDir(), nil, true).Verify(context.Background(), "Pinned Vendor", archivePath, "https://example.invalid/jdk.zip.sig"); err == nil {
+		t.Error("expected an unverifiable archive to be refused when signatures are required")
+	}
+}
+
+func TestVerifyUnknownDistributor(t *testing.T) {
+	isolatePinnedKeys(t, nil)
+	archivePath := filepath.Join(t.TempDir(), "jdk.zip")
+	os.WriteFile(archivePath, []byte("archive content"), 0644)
+
+	status, err := NewSignatureVerifier(t.TempDir(), nil, false).Verify(context.Background(), "Unknown Vendor", archivePath, "https://example.invalid/jdk.zip.sig")
+	if err != nil || status != SignatureNoKey {
+		t.Errorf("got %q, %v; want no-key without an error", status, err)
+	}
+	if _, err := NewSignatureVerifier(t.TempDir(), nil, true).Verify(context.Background(), "Unknown Vendor", archivePath, "https://example.invalid/jdk.zip.sig"); err == nil {
+		t.Error("expected an unverifiable archive to be refused when signatures are required")
+	}
+}
+
+func TestEmbeddedKeysArePinned(t *testing.T) {
+	files, err := fs.Glob(embeddedKeys, "keys/*.asc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pinned := map[string]bool{}
+	for _, fingerprints := range pinnedKeyFingerprints {
+		for _, fpr := range fingerprints {
+			pinned[strings.ToUpper(fpr)] = true
+		}
+	}
+
+	for _, file := range files {
+		data, _ := embeddedKeys.ReadFile(file)
+		entities, err := openpgp.ReadArmoredKeyRing(bytes.NewReader(data))
+		if err != nil {
+			t.Errorf("%s: %v", file, err)
+			continue
+		}
+		for _, entity := range entities {
+			if !pinned[fingerprint(entity)] {
+				t.Errorf("%s holds key %s, which no distributor pins", file, fingerprint(entity))
+			}
+		}
+	}
+}
+
+func TestEmbeddedAdoptiumKey(t *testing.T) {
+	// Without it every Temurin install depends on reaching the key server
+	entity, err := embeddedKey(adoptiumKeyFingerprint)
+	if err != nil || entity == nil {
+		t.Fatalf("keys/ holds no key with fingerprint %s (%v)", adoptiumKeyFingerprint, err)
+	}
+}
@@ -0,0 +1,181 @@
+package installer
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"jv/internal/java"
+)
+
+// smokeTestTimeout bounds each command of the smoke test; a JDK that needs
+// longer than this to print its version or compile one class is broken
+const smokeTestTimeout = 60 * time.Second
+
+// smokeTestMarker is printed by the HelloWorld program when it runs
+const smokeTestMarker = "jv smoke test OK"
+
+// helloWorldSource is compiled and run to check a JDK's compiler
+const helloWorldSource = `public class HelloWorld {
+    public static void main(String[] args) {
+        System.out.println("` + smokeTestMarker + `");
+    }
+}
+`
+
+// verifyInstall runs smokeTest with a spinner and reports the outcome
+func verifyInstall(ctx context.Context, jdkPath string, expectedVersion string, arch string, imageType ImageType, workDir string) error {
+	if !imageType.IsRuntime() {
+		return nil
+	}
+
+	var result string
+	var testErr error
+	spinnerErr := WithSpinner(ctx, "Verifying installation...", func(ctx context.Context) error {
+		result, testErr = smokeTest(ctx, jdkPath, expectedVersion, arch, imageType, workDir)
+		return nil
+	})
+	if spinnerErr != nil {
+		return spinnerErr
+	}
+	if testErr != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return fmt.Errorf("smoke test failed, installation rolled back: %w", testErr)
+	}
+
+	if !canRunArch(arch) {
+		fmt.Printf("⚠ Smoke test limited to the release file: a %s JDK cannot run on this %s machine\n", arch, java.HostArch())
+	} else {
+		fmt.Printf("✓ Smoke test passed (%s)\n", result)
+	}
+	return nil
+}
+
+// smokeTest checks that a freshly extracted JDK works before it is moved into
+// place: the release file must report expectedVersion (skipped when empty),
+// java -version must succeed and, for JDK images, a HelloWorld class must
+// compile and run. workDir is a scratch directory for the compiled class.
+// It returns the first line of java -version; JDKs that cannot run on this
+// machine (see canRunArch) only get the release file check.
+func smokeTest(ctx context.Context, jdkPath string, expectedVersion string, arch string, imageType ImageType, workDir string) (string, error) {
+	if !imageType.IsRuntime() {
+		return "", nil
+	}
+
+	release, err := java.ReadReleaseFile(jdkPath)
+	if err != nil {
+		return "", fmt.Errorf("cannot read release file: %w", err)
+	}
+	if actual := releaseFullVersion(release); expectedVersion != "" && !versionsMatch(expectedVersion, actual) {
+		return "", fmt.Errorf("release file reports version %q, expected %s", actual, expectedVersion)
+	}
+
+	if !canRunArch(arch) {
+		return "", nil
+	}
+
+	output, err := runSmokeCommand(ctx, filepath.Join(jdkPath, "bin", javaBinaryName()), "-version")
+	if err != nil {
+		return "", fmt.Errorf("java -version failed: %w", err)
+	}
+	versionLine, _, _ := strings.Cut(strings.TrimSpace(output), "\n")
+	versionLine = strings.TrimSpace(versionLine)
+
+	if imageType != ImageJDK {
+		return versionLine, nil
+	}
+
+	srcDir := filepath.Join(workDir, "src")
+	if err := os.MkdirAll(srcDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create smoke test directory: %w", err)
+	}
+	srcFile := filepath.Join(srcDir, "HelloWorld.java")
+	if err := os.WriteFile(srcFile, []byte(helloWorldSource), 0644); err != nil {
+		return "", fmt.Errorf("failed to write smoke test program: %w", err)
+	}
+
+	javac := filepath.Join(jdkPath, "bin", "javac"+filepath.Ext(javaBinaryName()))
+	if _, err := runSmokeCommand(ctx, javac, "-d", workDir, srcFile); err != nil {
+		return "", fmt.Errorf("javac failed to compile HelloWorld: %w", err)
+	}
+
+	output, err = runSmokeCommand(ctx, filepath.Join(jdkPath, "bin", javaBinaryName()), "-cp", workDir, "HelloWorld")
+	if err != nil {
+		return "", fmt.Errorf("java failed to run HelloWorld: %w", err)
+	}
+	if !strings.Contains(output, smokeTestMarker) {
+		return "", fmt.Errorf("HelloWorld printed %q, expected %q", strings.TrimSpace(output), smokeTestMarker)
+	}
+
+	return versionLine, nil
+}
+
+// runSmokeCommand runs a JDK tool with smokeTestTimeout and returns its combined output
+func runSmokeCommand(ctx context.Context, name string, args ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, smokeTestTimeout)
+	defer cancel()
+
+	var output bytes.Buffer
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	// Settings meant for the user's own JVMs must not break the test
+	cmd.Env = append(os.Environ(), "JAVA_TOOL_OPTIONS=", "JDK_JAVA_OPTIONS=", "_JAVA_OPTIONS=", "CLASSPATH=")
+
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return "", fmt.Errorf("timed out after %s", smokeTestTimeout)
+		}
+		if out := strings.TrimSpace(output.String()); out != "" {
+			return "", fmt.Errorf("%w: %s", err, out)
+		}
+		return "", err
+	}
+	return output.String(), nil
+}
+
+// canRunArch reports whether binaries for arch can be run on this machine,
+// natively or through the emulation built into Windows
+func canRunArch(arch string) bool {
+	host := java.HostArch()
+	switch {
+	case arch == "" || arch == host:
+		return true
+	case arch == "x86":
+		return host == "x64" || host == "aarch64"
+	case arch == "x64":
+		return host == "aarch64"
+	default:
+		return false
+	}
+}
+
+// versionsMatch compares a requested version with the one in a release file.
+// Java 8 release files use 1.8.0_392-b08 for 8u392-b08, and some vendors leave
+// out the build number, so "21.0.5" matches "21.0.5+11".
+func versionsMatch(expected string, actual string) bool {
+	canonical := func(version string) string {
+		version = strings.TrimSuffix(strings.TrimSpace(version), "-LTS")
+		if rest, ok := strings.CutPrefix(version, "1.8.0_"); ok {
+			version = "8u" + rest
+		}
+		return version
+	}
+
+	expected, actual = canonical(expected), canonical(actual)
+	if expected == actual {
+		return true
+	}
+	short, long := expected, actual
+	if len(short) > len(long) {
+		short, long = long, short
+	}
+	return short != "" && strings.HasPrefix(long, short) && strings.ContainsRune("+-", rune(long[len(short)]))
+}
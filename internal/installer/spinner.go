@@ -1,6 +1,7 @@
 package installer
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -14,13 +15,15 @@ type spinnerFinishedMsg struct {
 }
 
 type spinnerModel struct {
-	spinner  spinner.Model
-	message  string
-	quitting bool
-	err      error
+	spinner     spinner.Model
+	message     string
+	quitting    bool
+	interrupted bool
+	cancel      context.CancelFunc
+	err         error
 }
 
-func newSpinnerModel(message string) spinnerModel {
+func newSpinnerModel(message string, cancel context.CancelFunc) spinnerModel {
 	s := spinner.New()
 	s.Spinner = spinner.Dot
 	s.Style = lipgloss.NewStyle().Foreground(lipgloss.Color("12"))
@@ -28,6 +31,7 @@ func newSpinnerModel(message string) spinnerModel {
 	return spinnerModel{
 		spinner: s,
 		message: message,
+		cancel:  cancel,
 	}
 }
 
@@ -39,6 +43,9 @@ func (m spinnerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
 		if msg.String() == "ctrl+c" {
+			// Stop the background work too, not just the animation
+			m.cancel()
+			m.interrupted = true
 			m.quitting = true
 			return m, tea.Quit
 		}
@@ -66,21 +73,47 @@ func (m spinnerModel) View() string {
 	return fmt.Sprintf("\n %s %s\n\n", m.spinner.View(), m.message)
 }
 
-// WithSpinner runs a function with a spinner animation
-func WithSpinner(message string, fn func() error) error {
-	p := tea.NewProgram(newSpinnerModel(message))
+// WithSpinner runs a function with a spinner animation. Pressing ctrl+c cancels
+// the context passed to fn; WithSpinner waits for fn to return either way and
+// reports the cancellation as ctx.Err(). Without a terminal (see ProgressMode)
+// the message is printed as a plain line instead.
+func WithSpinner(ctx context.Context, message string, fn func(ctx context.Context) error) error {
+	if mode := currentProgressMode(); mode != ProgressTTY {
+		if mode == ProgressPlain {
+			fmt.Println(message)
+		}
+		fn(ctx)
+		return ctx.Err()
+	}
+
+	fnCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	p := tea.NewProgram(newSpinnerModel(message, cancel), tea.WithContext(ctx))
 
 	// Run function in background
+	done := make(chan struct{})
 	go func() {
+		defer close(done)
 		time.Sleep(100 * time.Millisecond) // Give UI time to start
-		err := fn()
+		err := fn(fnCtx)
 		p.Send(spinnerFinishedMsg{err: err})
 	}()
 
 	// Run the spinner UI
-	if _, err := p.Run(); err != nil {
-		return err
+	model, runErr := p.Run()
+
+	// Never leave the function running once the spinner is gone
+	if runErr != nil {
+		cancel()
 	}
+	<-done
 
-	return nil
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if m, ok := model.(spinnerModel); ok && m.interrupted {
+		return context.Canceled
+	}
+	return runErr
 }
@@ -0,0 +1,113 @@
+package java
+
+import (
+	"bufio"
+	"debug/pe"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// archAliases maps the names used by Go, Windows, release files and vendors
+// to the vendor (Adoptium) naming used throughout jv
+var archAliases = map[string]string{
+	"x64":     "x64",
+	"amd64":   "x64",
+	"x86_64":  "x64",
+	"x86":     "x86",
+	"x32":     "x86",
+	"386":     "x86",
+	"i386":    "x86",
+	"i586":    "x86",
+	"i686":    "x86",
+	"aarch64": "aarch64",
+	"arm64":   "aarch64",
+	"arm":     "arm",
+	"arm32":   "arm",
+	"armv7":   "arm",
+	"armhf":   "arm",
+	"ppc64le": "ppc64le",
+	"ppc64":   "ppc64",
+	"s390x":   "s390x",
+	"riscv64": "riscv64",
+	"sparcv9": "sparcv9",
+}
+
+// NormalizeArch maps an architecture name such as "amd64", "x86_64" or "arm64"
+// to vendor naming ("x64", "aarch64", ...)
+func NormalizeArch(arch string) (string, error) {
+	name := strings.ToLower(strings.TrimSpace(arch))
+	if normalized, ok := archAliases[name]; ok {
+		return normalized, nil
+	}
+	return "", fmt.Errorf("unknown architecture %q (expected x64, x86, aarch64, arm, ppc64le, ppc64, s390x or riscv64)", arch)
+}
+
+// HostArch returns the architecture of the machine in vendor naming. On Windows
+// this is the native architecture even when jv itself runs under emulation.
+func HostArch() string {
+	for _, name := range []string{"PROCESSOR_ARCHITEW6432", "PROCESSOR_ARCHITECTURE"} {
+		if arch, err := NormalizeArch(os.Getenv(name)); err == nil {
+			return arch
+		}
+	}
+
+	if arch, err := NormalizeArch(runtime.GOARCH); err == nil {
+		return arch
+	}
+	return runtime.GOARCH
+}
+
+// GetArch returns the architecture of a Java installation in vendor naming,
+// read from its release file or else from the java.exe executable header.
+// It returns "" if neither can be read.
+func (d *Detector) GetArch(javaPath string) string {
+	if release, err := ReadReleaseFile(javaPath); err == nil {
+		if arch, err := NormalizeArch(release["OS_ARCH"]); err == nil {
+			return arch
+		}
+	}
+
+	file, err := pe.Open(filepath.Join(javaPath, "bin", "java.exe"))
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+
+	switch file.Machine {
+	case pe.IMAGE_FILE_MACHINE_AMD64:
+		return "x64"
+	case pe.IMAGE_FILE_MACHINE_I386:
+		return "x86"
+	case pe.IMAGE_FILE_MACHINE_ARM64:
+		return "aarch64"
+	case pe.IMAGE_FILE_MACHINE_ARMNT:
+		return "arm"
+	default:
+		return ""
+	}
+}
+
+// ReadReleaseFile parses the "release" file at the root of a JDK, which holds
+// KEY="value" lines such as JAVA_VERSION, JAVA_RUNTIME_VERSION and OS_ARCH
+func ReadReleaseFile(javaPath string) (map[string]string, error) {
+	file, err := os.Open(filepath.Join(javaPath, "release"))
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	values := make(map[string]string)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		key, value, found := strings.Cut(scanner.Text(), "=")
+		if !found {
+			continue
+		}
+		values[strings.TrimSpace(key)] = strings.Trim(strings.TrimSpace(value), `"`)
+	}
+
+	return values, scanner.Err()
+}
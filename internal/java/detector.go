@@ -67,7 +67,7 @@ func (d *Detector) FindAll() ([]Version, error) {
 			if d.IsValidJavaPath(javaPath) {
 				version := d.GetVersion(javaPath)
 				key := strings.ToLower(filepath.Clean(javaPath))
-				seen[key] = item{v: Version{Version: version, Path: filepath.Clean(javaPath), IsCustom: false}}
+				seen[key] = item{v: Version{Version: version, Path: filepath.Clean(javaPath), IsCustom: false, IsJRE: d.IsJRE(javaPath), Arch: d.GetArch(javaPath)}}
 			}
 		}
 	}
@@ -80,7 +80,7 @@ func (d *Detector) FindAll() ([]Version, error) {
 				key := strings.ToLower(norm)
 				version := d.GetVersion(norm)
 				// If already seen as auto, upgrade to custom; else add as custom
-				seen[key] = item{v: Version{Version: version, Path: norm, IsCustom: true}}
+				seen[key] = item{v: Version{Version: version, Path: norm, IsCustom: true, IsJRE: d.IsJRE(norm), Arch: d.GetArch(norm)}}
 			}
 		}
 	}
@@ -100,6 +100,12 @@ func (d *Detector) IsValidJavaPath(path string) bool {
 	return err == nil
 }
 
+// IsJRE reports whether a Java installation is a runtime without development tools
+func (d *Detector) IsJRE(path string) bool {
+	_, err := os.Stat(filepath.Join(path, "bin", "javac.exe"))
+	return err != nil
+}
+
 // IsValidSearchPath checks if a path is a valid directory to search for Java installations
 func (d *Detector) IsValidSearchPath(path string) bool {
 	info, err := os.Stat(path)
@@ -171,6 +177,13 @@ func (d *Detector) parseVersionFromDirName(dirName string) string {
 		return matches[1]
 	}
 
+	// Pattern: 21.0.5+11-x64 (jv install layout)
+	re = regexp.MustCompile(`^(\d+(?:\.\d+)*(?:\+\d+)?)`)
+	matches = re.FindStringSubmatch(dirName)
+	if len(matches) > 1 {
+		return matches[1]
+	}
+
 	// Return dir name as-is if no pattern matches
 	return dirName
 }
@@ -2,11 +2,13 @@ package java
 
 import (
 	"fmt"
+	"os"
 	"time"
 
 	"github.com/charmbracelet/bubbles/spinner"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/term"
 )
 
 type spinnerFinishedMsg struct{}
@@ -57,8 +59,13 @@ func (m scannerModel) View() string {
 	return fmt.Sprintf(" %s Scanning for Java installations...\n", m.spinner.View())
 }
 
-// WithScanner runs a function with a scanner animation
+// WithScanner runs a function with a scanner animation. Without a terminal
+// (CI logs, redirected output) the function simply runs.
 func WithScanner(fn func() error) error {
+	if !term.IsTerminal(os.Stdout.Fd()) || !term.IsTerminal(os.Stdin.Fd()) {
+		return fn()
+	}
+
 	p := tea.NewProgram(newScannerModel())
 
 	// Run function in background
@@ -1,8 +1,66 @@
 package java
 
+import (
+	"strconv"
+	"strings"
+)
+
 // Version represents a Java installation
 type Version struct {
 	Version  string // Version string (e.g., "17.0.1", "1.8.0_322")
 	Path     string // Full path to Java installation
 	IsCustom bool   // Whether this is from custom paths or auto-detected
+	IsJRE    bool   // Runtime only: there is no javac next to java
+	Arch     string // Architecture in vendor naming (e.g. "x64", "aarch64"), "" if unknown
+}
+
+// ParseVersion returns the numeric parts of a Java version without its build
+// number: [8 0 392] for "1.8.0_392" or "8u392-b08" and [21 0 5] for "21.0.5+11"
+func ParseVersion(version string) []int {
+	version = strings.TrimSpace(version)
+	if idx := strings.IndexAny(version, "+-"); idx >= 0 {
+		version = version[:idx]
+	}
+	if rest, ok := strings.CutPrefix(version, "1."); ok {
+		version = strings.Replace(rest, "_", ".", 1)
+	} else if major, update, ok := strings.Cut(version, "u"); ok {
+		version = major + ".0." + update
+	}
+
+	var parts []int
+	for _, field := range strings.Split(version, ".") {
+		n, err := strconv.Atoi(field)
+		if err != nil {
+			break
+		}
+		parts = append(parts, n)
+	}
+	return parts
+}
+
+// CompareVersions compares two Java versions by their numeric parts, ignoring
+// build numbers. It returns -1, 0 or 1 like strings.Compare.
+func CompareVersions(a string, b string) int {
+	return CompareParsedVersions(ParseVersion(a), ParseVersion(b))
+}
+
+// CompareParsedVersions compares versions returned by ParseVersion, missing
+// parts counting as 0
+func CompareParsedVersions(a []int, b []int) int {
+	for idx := 0; idx < len(a) || idx < len(b); idx++ {
+		var left, right int
+		if idx < len(a) {
+			left = a[idx]
+		}
+		if idx < len(b) {
+			right = b[idx]
+		}
+		if left != right {
+			if left < right {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
 }
@@ -0,0 +1,59 @@
+package java
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseVersion(t *testing.T) {
+	tests := []struct {
+		version string
+		want    []int
+	}{
+		{"21.0.5+11", []int{21, 0, 5}},
+		{"21.0.5+11-LTS", []int{21, 0, 5}},
+		{"21+35", []int{21}},
+		{"21", []int{21}},
+		{"17.0.8.1+1", []int{17, 0, 8, 1}},
+		{"11.0.24-ea", []int{11, 0, 24}},
+		{"1.8.0_392", []int{8, 0, 392}},
+		{"1.8.0_392-b08", []int{8, 0, 392}},
+		{"8u392", []int{8, 0, 392}},
+		{"8u392-b08", []int{8, 0, 392}},
+		{" 17.0.1 ", []int{17, 0, 1}},
+		{"", nil},
+		{"jdk-21", nil},
+	}
+	for _, tt := range tests {
+		if got := ParseVersion(tt.version); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseVersion(%q) = %v, want %v", tt.version, got, tt.want)
+		}
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		// Java 8 spellings are the same release
+		{"1.8.0_392", "8u392", 0},
+		{"8u392-b08", "1.8.0_392", 0},
+		{"1.8.0_382", "8u392", -1},
+		{"8u402", "1.8.0_392-b08", 1},
+		// Build numbers are ignored
+		{"21.0.5+11", "21.0.5+12", 0},
+		{"8u392-b08", "8u392-b09", 0},
+		// Missing parts count as 0, numbers compare numerically
+		{"21", "21.0.0", 0},
+		{"21.0.5", "21.0.10", -1},
+		{"17.0.8.1+1", "17.0.8+7", 1},
+		{"11.0.2", "1.8.0_999", 1},
+		{"17.0.12", "21", -1},
+	}
+	for _, tt := range tests {
+		if got := CompareVersions(tt.a, tt.b); got != tt.want {
+			t.Errorf("CompareVersions(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,310 @@
+// Package lifecycle knows when Java feature releases were published and until
+// when their vendors support them. A dataset is built into jv and can be
+// refreshed from endoflife.date with jv catalog refresh.
+package lifecycle
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//go:embed lifecycle.json
+var embedded []byte
+
+const (
+	fileName   = "lifecycle.json"
+	dateLayout = "2006-01-02"
+	apiBase    = "https://endoflife.date/api"
+)
+
+// Cycle is the lifecycle of one feature release of a vendor. Dates use the
+// YYYY-MM-DD form; an empty EOL means no end of life has been announced.
+type Cycle struct {
+	Major          string `json:"major"`
+	Release        string `json:"release"`
+	PremierSupport string `json:"premier_support,omitempty"` // End of premier support where the vendor has extended support
+	EOL            string `json:"eol,omitempty"`
+	LTS            bool   `json:"lts,omitempty"`
+
+	Vendor string `json:"-"` // Name of the vendor the cycle was found for, set by Lookup
+}
+
+// EOLDate returns the end-of-life date, if one is known
+func (c Cycle) EOLDate() (time.Time, bool) {
+	date, err := time.Parse(dateLayout, c.EOL)
+	return date, err == nil
+}
+
+// IsEOL reports whether the release is past its end of life at now
+func (c Cycle) IsEOL(now time.Time) bool {
+	date, ok := c.EOLDate()
+	return ok && !now.Before(date)
+}
+
+// Vendor holds the cycles published by one vendor
+type Vendor struct {
+	Name    string   `json:"name"`
+	Aliases []string `json:"aliases,omitempty"` // Other names, such as the IMPLEMENTOR of release files
+	Product string   `json:"product"`           // endoflife.date product name
+	Cycles  []Cycle  `json:"cycles"`
+}
+
+// Dataset is the lifecycle data of every known vendor. The first vendor is
+// used for JDKs whose vendor is unknown.
+type Dataset struct {
+	Updated string   `json:"updated"`
+	Vendors []Vendor `json:"vendors"`
+}
+
+// Embedded returns the dataset built into jv
+func Embedded() *Dataset {
+	var data Dataset
+	if err := json.Unmarshal(embedded, &data); err != nil {
+		panic("lifecycle: invalid embedded dataset: " + err.Error())
+	}
+	return &data
+}
+
+// Load returns the dataset refreshed into dir, or the built-in one when dir
+// has none, it cannot be read or it is older than the built-in one
+func Load(dir string) *Dataset {
+	builtin := Embedded()
+	raw, err := os.ReadFile(filepath.Join(dir, fileName))
+	if err != nil {
+		return builtin
+	}
+	var data Dataset
+	if err := json.Unmarshal(raw, &data); err != nil || len(data.Vendors) == 0 || data.Updated < builtin.Updated {
+		return builtin
+	}
+	return &data
+}
+
+// Lookup returns the cycle of major for vendor, matched against the vendor
+// names and aliases without regard to case. Unknown vendors fall back to the
+// first vendor of the dataset.
+func (d *Dataset) Lookup(vendor string, major string) (Cycle, bool) {
+	if len(d.Vendors) == 0 {
+		return Cycle{}, false
+	}
+
+	match := &d.Vendors[0]
+	vendor = strings.TrimSpace(vendor)
+	for idx := range d.Vendors {
+		if d.Vendors[idx].matches(vendor) {
+			match = &d.Vendors[idx]
+			break
+		}
+	}
+
+	for _, cycle := range match.Cycles {
+		if cycle.Major == major {
+			cycle.Vendor = match.Name
+			return cycle, true
+		}
+	}
+	return Cycle{}, false
+}
+
+// VendorName returns the dataset's name of the vendor called name, such as
+// "Amazon Corretto" for "Amazon.com Inc.", or name itself for unknown vendors
+func (d *Dataset) VendorName(name string) string {
+	name = strings.TrimSpace(name)
+	for idx := range d.Vendors {
+		if d.Vendors[idx].matches(name) {
+			return d.Vendors[idx].Name
+		}
+	}
+	return name
+}
+
+// matches reports whether name is the vendor's name or one of its aliases
+func (v *Vendor) matches(name string) bool {
+	if name == "" {
+		return false
+	}
+	if strings.EqualFold(v.Name, name) {
+		return true
+	}
+	for _, alias := range v.Aliases {
+		if strings.EqualFold(alias, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// Major returns the feature release of a Java version, e.g. "8" for
+// "1.8.0_392" or "8u392" and "21" for "21.0.5+11"
+func Major(version string) string {
+	version = strings.TrimSpace(version)
+	if rest, ok := strings.CutPrefix(version, "1."); ok {
+		version = rest
+	}
+	end := strings.IndexFunc(version, func(r rune) bool { return r < '0' || r > '9' })
+	if end < 0 {
+		return version
+	}
+	return version[:end]
+}
+
+// apiCycle is a release cycle as published by endoflife.date. eol, support
+// and lts are either dates or booleans.
+type apiCycle struct {
+	Cycle       string          `json:"cycle"`
+	ReleaseDate string          `json:"releaseDate"`
+	EOL         json.RawMessage `json:"eol"`
+	Support     json.RawMessage `json:"support"`
+	LTS         json.RawMessage `json:"lts"`
+}
+
+// Refresh fetches the current lifecycle of every vendor from endoflife.date,
+// merges it into the dataset from dir and writes the result back. Vendors that
+// cannot be fetched keep their previous data. It returns how many vendors were
+// refreshed; the error reports the vendors that were not.
+func Refresh(ctx context.Context, dir string) (int, error) {
+	data := Load(dir)
+	today := time.Now().Format(dateLayout)
+
+	refreshed := 0
+	var errs []error
+	for idx := range data.Vendors {
+		vendor := &data.Vendors[idx]
+		cycles, err := fetch(ctx, vendor.Product)
+		if err != nil {
+			if ctx.Err() != nil {
+				return 0, ctx.Err()
+			}
+			errs = append(errs, fmt.Errorf("%s: %w", vendor.Name, err))
+			continue
+		}
+		vendor.merge(cycles, today)
+		refreshed++
+	}
+
+	if refreshed == 0 {
+		return 0, errors.Join(errs...)
+	}
+	data.Updated = today
+	if err := save(dir, data); err != nil {
+		return 0, fmt.Errorf("failed to save lifecycle data: %w", err)
+	}
+	return refreshed, errors.Join(errs...)
+}
+
+// fetch downloads the cycles of an endoflife.date product
+func fetch(ctx context.Context, product string) ([]apiCycle, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/%s.json", apiBase, product), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("endoflife.date returned status %d", resp.StatusCode)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	var cycles []apiCycle
+	if err := json.Unmarshal(body, &cycles); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+	return cycles, nil
+}
+
+// merge updates the vendor's cycles with the published ones. Dates the API
+// leaves out keep their previous value; an end of life that is only flagged,
+// without a date, is recorded as today.
+func (v *Vendor) merge(published []apiCycle, today string) {
+	byMajor := make(map[string]int, len(v.Cycles))
+	for idx, cycle := range v.Cycles {
+		byMajor[cycle.Major] = idx
+	}
+
+	for _, api := range published {
+		major := Major(api.Cycle)
+		if _, err := strconv.Atoi(major); err != nil || major != api.Cycle {
+			continue
+		}
+		idx, ok := byMajor[major]
+		if !ok {
+			v.Cycles = append(v.Cycles, Cycle{Major: major})
+			idx = len(v.Cycles) - 1
+			byMajor[major] = idx
+		}
+
+		cycle := &v.Cycles[idx]
+		if api.ReleaseDate != "" {
+			cycle.Release = api.ReleaseDate
+		}
+		if eol, flagged := apiDate(api.EOL); eol != "" {
+			cycle.EOL = eol
+		} else if flagged && cycle.EOL == "" {
+			cycle.EOL = today
+		}
+		if support, _ := apiDate(api.Support); support != "" && support != cycle.EOL {
+			cycle.PremierSupport = support
+		}
+		if since, flagged := apiDate(api.LTS); since != "" || flagged {
+			cycle.LTS = true
+		}
+	}
+
+	sort.Slice(v.Cycles, func(a, b int) bool {
+		left, _ := strconv.Atoi(v.Cycles[a].Major)
+		right, _ := strconv.Atoi(v.Cycles[b].Major)
+		return left < right
+	})
+}
+
+// apiDate decodes a field that is either a date or a boolean, returning the
+// date or, for a boolean, whether it was true
+func apiDate(raw json.RawMessage) (string, bool) {
+	var date string
+	if err := json.Unmarshal(raw, &date); err == nil {
+		if _, err := time.Parse(dateLayout, date); err == nil {
+			return date, false
+		}
+		return "", false
+	}
+	var flag bool
+	if err := json.Unmarshal(raw, &flag); err == nil {
+		return "", flag
+	}
+	return "", false
+}
+
+// save writes the dataset to dir, replacing the previous file
+func save(dir string, data *Dataset) error {
+	raw, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	path := filepath.Join(dir, fileName)
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, raw, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
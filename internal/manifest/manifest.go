@@ -0,0 +1,242 @@
+// Package manifest records the files of an installed JDK with their hashes,
+// so that files removed, changed or added after the install can be found
+// later, for example by an antivirus quarantine or an accidental edit.
+package manifest
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ErrNotRecorded is returned by Load for installations without a manifest,
+// such as JDKs jv did not install or installed before manifests were kept
+var ErrNotRecorded = errors.New("no manifest recorded")
+
+// File is one entry of a manifest
+type File struct {
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256,omitempty"`
+	Link   string `json:"link,omitempty"` // Target of a symbolic link, which is not hashed
+}
+
+// Manifest lists the files below Root, keyed by their slash-separated path
+// relative to Root
+type Manifest struct {
+	Root    string          `json:"root"`
+	Created time.Time       `json:"created"`
+	Files   map[string]File `json:"files"`
+}
+
+// Report lists the paths, relative to the root, that differ from a manifest
+type Report struct {
+	Missing  []string
+	Modified []string
+	Extra    []string
+}
+
+// Clean reports whether the installation matches its manifest
+func (r *Report) Clean() bool {
+	return len(r.Missing) == 0 && len(r.Modified) == 0 && len(r.Extra) == 0
+}
+
+// Build hashes every file below root
+func Build(ctx context.Context, root string) (*Manifest, error) {
+	root = filepath.Clean(root)
+	m := &Manifest{Root: root, Created: time.Now().UTC(), Files: map[string]File{}}
+
+	err := walk(ctx, root, func(rel string, path string, info fs.FileInfo) error {
+		file, err := describe(path, info)
+		if err != nil {
+			return err
+		}
+		m.Files[rel] = file
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to build manifest of %s: %w", root, err)
+	}
+	return m, nil
+}
+
+// Verify compares the files below the root with the manifest. Sizes are
+// compared before hashes, so only files of unchanged size are read.
+func (m *Manifest) Verify(ctx context.Context) (*Report, error) {
+	report := &Report{}
+	seen := make(map[string]bool, len(m.Files))
+
+	err := walk(ctx, m.Root, func(rel string, path string, info fs.FileInfo) error {
+		expected, ok := m.Files[rel]
+		if !ok {
+			report.Extra = append(report.Extra, rel)
+			return nil
+		}
+		seen[rel] = true
+
+		isLink := info.Mode()&fs.ModeSymlink != 0
+		if isLink != (expected.Link != "") || !isLink && info.Size() != expected.Size {
+			report.Modified = append(report.Modified, rel)
+			return nil
+		}
+		actual, err := describe(path, info)
+		if err != nil {
+			return err
+		}
+		if actual != expected {
+			report.Modified = append(report.Modified, rel)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify %s: %w", m.Root, err)
+	}
+
+	for rel := range m.Files {
+		if !seen[rel] {
+			report.Missing = append(report.Missing, rel)
+		}
+	}
+	sort.Strings(report.Missing)
+	sort.Strings(report.Modified)
+	sort.Strings(report.Extra)
+	return report, nil
+}
+
+// Refresh records the current state of path, a file below the root that was
+// changed on purpose, such as a truststore jv imported certificates into
+func (m *Manifest) Refresh(path string) error {
+	rel, err := filepath.Rel(m.Root, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return fmt.Errorf("%s is not inside %s", path, m.Root)
+	}
+	rel = filepath.ToSlash(rel)
+
+	info, err := os.Lstat(path)
+	if os.IsNotExist(err) {
+		delete(m.Files, rel)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	file, err := describe(path, info)
+	if err != nil {
+		return err
+	}
+	m.Files[rel] = file
+	return nil
+}
+
+// PathFor returns the manifest file of the installation at root in dir
+func PathFor(dir string, root string) string {
+	// Paths are compared case-insensitively elsewhere in jv, and so here
+	sum := sha256.Sum256([]byte(strings.ToLower(filepath.Clean(root))))
+	return filepath.Join(dir, hex.EncodeToString(sum[:8])+".json")
+}
+
+// Load reads the manifest of the installation at root from dir. It returns
+// ErrNotRecorded when there is none.
+func Load(dir string, root string) (*Manifest, error) {
+	data, err := os.ReadFile(PathFor(dir, root))
+	if os.IsNotExist(err) {
+		return nil, ErrNotRecorded
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest: %w", err)
+	}
+	if m.Files == nil {
+		m.Files = map[string]File{}
+	}
+	m.Root = filepath.Clean(root)
+	return &m, nil
+}
+
+// Save writes the manifest to dir, replacing the previous one of the same root
+func (m *Manifest) Save(dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create manifest directory: %w", err)
+	}
+	data, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+
+	path := PathFor(dir, m.Root)
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+	return nil
+}
+
+// walk calls fn for every regular file and symbolic link below root.
+// Directories themselves are not recorded, so an empty directory is ignored.
+func walk(ctx context.Context, root string, fn func(rel string, path string, info fs.FileInfo) error) error {
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() && info.Mode()&fs.ModeSymlink == 0 {
+			return nil
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		return fn(filepath.ToSlash(rel), path, info)
+	})
+}
+
+// describe returns the manifest entry of the file at path
+func describe(path string, info fs.FileInfo) (File, error) {
+	if info.Mode()&fs.ModeSymlink != 0 {
+		target, err := os.Readlink(path)
+		if err != nil {
+			return File{}, err
+		}
+		return File{Link: filepath.ToSlash(target)}, nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return File{}, err
+	}
+	defer f.Close()
+
+	hasher := sha256.New()
+	size, err := io.Copy(hasher, f)
+	if err != nil {
+		return File{}, err
+	}
+	return File{Size: size, SHA256: hex.EncodeToString(hasher.Sum(nil))}, nil
+}
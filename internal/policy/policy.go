@@ -0,0 +1,158 @@
+// Package policy enforces an organization's rules on which JDKs may be
+// installed and used. The rules are read from a JSON policy file named by the
+// JV_POLICY environment variable or policy_file in jv.json.
+package policy
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"jv/internal/config"
+	"jv/internal/java"
+	"jv/internal/lifecycle"
+)
+
+// EnvVar names the policy file and takes precedence over policy_file
+const EnvVar = "JV_POLICY"
+
+// Policy holds the rules of a policy file. Rules that are left out allow everything.
+type Policy struct {
+	// AllowedDistributors lists the vendors JDKs may come from, matched
+	// against distributor names and release file IMPLEMENTORs
+	AllowedDistributors []string `json:"allowed_distributors,omitempty"`
+	// AllowedMajors lists the feature releases that may be used, e.g. ["17", "21"]
+	AllowedMajors []string `json:"allowed_majors,omitempty"`
+	// MinimumVersions maps a feature release to its lowest allowed version,
+	// e.g. "21": "21.0.4"
+	MinimumVersions map[string]string `json:"minimum_versions,omitempty"`
+
+	Path string `json:"-"` // File the policy was read from
+}
+
+// Violation is the error returned for a JDK that breaks a rule of the policy
+type Violation struct {
+	Rule   string // "allowed_distributors", "allowed_majors" or "minimum_versions"
+	Reason string
+	Path   string // Policy file
+}
+
+func (v *Violation) Error() string {
+	return fmt.Sprintf("blocked by policy: %s (rule %s in %s)", v.Reason, v.Rule, v.Path)
+}
+
+// PathFor returns the policy file configured for cfg, "" if there is none
+func PathFor(cfg *config.Config) string {
+	if path := strings.TrimSpace(os.Getenv(EnvVar)); path != "" {
+		return path
+	}
+	return strings.TrimSpace(cfg.PolicyFile)
+}
+
+// Load reads the policy configured for cfg. It returns nil without an error
+// when no policy is configured; a configured policy that cannot be read is an
+// error, so that a missing file never lifts the restrictions.
+func Load(cfg *config.Config) (*Policy, error) {
+	path := PathFor(cfg)
+	if path == "" {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read policy file: %w", err)
+	}
+	data = bytes.TrimPrefix(data, []byte{0xEF, 0xBB, 0xBF})
+
+	// A misspelled rule would silently allow everything, so unknown fields are errors
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	var policy Policy
+	if err := decoder.Decode(&policy); err != nil {
+		return nil, fmt.Errorf("invalid policy file %s: %w", path, err)
+	}
+	for major, minimum := range policy.MinimumVersions {
+		if lifecycle.Major(minimum) != major {
+			return nil, fmt.Errorf("invalid policy file %s: minimum version %q is not a Java %s version", path, minimum, major)
+		}
+	}
+
+	policy.Path = path
+	return &policy, nil
+}
+
+// Rules returns the names of the rules the policy sets
+func (p *Policy) Rules() []string {
+	var rules []string
+	if len(p.AllowedDistributors) > 0 {
+		rules = append(rules, "allowed_distributors")
+	}
+	if len(p.AllowedMajors) > 0 {
+		rules = append(rules, "allowed_majors")
+	}
+	if len(p.MinimumVersions) > 0 {
+		rules = append(rules, "minimum_versions")
+	}
+	return rules
+}
+
+// Check returns a *Violation when a JDK of vendor with version breaks a rule.
+// A nil Policy allows everything.
+func (p *Policy) Check(vendor string, version string) error {
+	if p == nil {
+		return nil
+	}
+
+	if len(p.AllowedDistributors) > 0 && !p.allowsDistributor(vendor) {
+		allowed := strings.Join(p.AllowedDistributors, ", ")
+		if strings.TrimSpace(vendor) == "" {
+			return &Violation{Rule: "allowed_distributors", Path: p.Path,
+				Reason: fmt.Sprintf("the vendor of Java %s is unknown; allowed distributors: %s", version, allowed)}
+		}
+		return &Violation{Rule: "allowed_distributors", Path: p.Path,
+			Reason: fmt.Sprintf("%s JDKs are not allowed; allowed distributors: %s", vendor, allowed)}
+	}
+
+	major := lifecycle.Major(version)
+	if len(p.AllowedMajors) > 0 && !contains(p.AllowedMajors, major) {
+		allowed := append([]string(nil), p.AllowedMajors...)
+		sort.Slice(allowed, func(a, b int) bool { return java.CompareVersions(allowed[a], allowed[b]) < 0 })
+		return &Violation{Rule: "allowed_majors", Path: p.Path,
+			Reason: fmt.Sprintf("Java %s is not allowed; allowed releases: %s", major, strings.Join(allowed, ", "))}
+	}
+
+	if minimum, ok := p.MinimumVersions[major]; ok && java.CompareVersions(version, minimum) < 0 {
+		return &Violation{Rule: "minimum_versions", Path: p.Path,
+			Reason: fmt.Sprintf("Java %s is older than %s, the minimum for Java %s", version, minimum, major)}
+	}
+	return nil
+}
+
+// allowsDistributor reports whether vendor is one of the allowed
+// distributors, comparing the names known for each vendor
+func (p *Policy) allowsDistributor(vendor string) bool {
+	if strings.TrimSpace(vendor) == "" {
+		return false
+	}
+	names := lifecycle.Embedded()
+	canonical := names.VendorName(vendor)
+	for _, allowed := range p.AllowedDistributors {
+		if strings.EqualFold(names.VendorName(allowed), canonical) {
+			return true
+		}
+	}
+	return false
+}
+
+// contains reports whether list holds value
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if strings.TrimSpace(item) == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,93 @@
+package policy
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"jv/internal/config"
+)
+
+func TestCheck(t *testing.T) {
+	policy := &Policy{
+		AllowedDistributors: []string{"Eclipse Adoptium"},
+		AllowedMajors:       []string{"8", "17", "21"},
+		MinimumVersions:     map[string]string{"8": "8u392", "21": "21.0.4"},
+		Path:                "policy.json",
+	}
+
+	tests := []struct {
+		vendor  string
+		version string
+		rule    string // Rule of the expected violation, "" if allowed
+	}{
+		{"Eclipse Adoptium", "21.0.4+7", ""},
+		{"Eclipse Adoptium", "21.0.10+7", ""},
+		{"Temurin", "21.0.5", ""}, // Alias of the allowed distributor
+		{"Eclipse Adoptium", "21.0.3+9", "minimum_versions"},
+		{"Eclipse Adoptium", "17.0.1", ""}, // No minimum for 17
+		{"Eclipse Adoptium", "1.8.0_392", ""},
+		{"Eclipse Adoptium", "8u392-b08", ""},
+		{"Eclipse Adoptium", "1.8.0_382", "minimum_versions"},
+		{"Eclipse Adoptium", "8u382-b05", "minimum_versions"},
+		{"Eclipse Adoptium", "11.0.24", "allowed_majors"},
+		{"Oracle Corporation", "21.0.5", "allowed_distributors"},
+		{"", "21.0.5", "allowed_distributors"},
+	}
+	for _, tt := range tests {
+		err := policy.Check(tt.vendor, tt.version)
+		var violation *Violation
+		switch {
+		case tt.rule == "" && err != nil:
+			t.Errorf("Check(%q, %q) = %v, want allowed", tt.vendor, tt.version, err)
+		case tt.rule != "" && (!errors.As(err, &violation) || violation.Rule != tt.rule):
+			t.Errorf("Check(%q, %q) = %v, want a %s violation", tt.vendor, tt.version, err, tt.rule)
+		}
+	}
+
+	var none *Policy
+	if err := none.Check("Oracle Corporation", "11.0.1"); err != nil {
+		t.Errorf("a nil policy must allow everything, got %v", err)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string // "" when the policy is valid
+	}{
+		{"valid", `{"allowed_majors": ["21"], "minimum_versions": {"21": "21.0.4", "8": "1.8.0_392"}}`, ""},
+		{"byte order mark", "\xEF\xBB\xBF" + `{"allowed_majors": ["21"]}`, ""},
+		{"misspelled rule", `{"allowed_major": ["21"]}`, "unknown field"},
+		{"minimum of another release", `{"minimum_versions": {"21": "17.0.12"}}`, "is not a Java 21 version"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "policy.json")
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			t.Setenv(EnvVar, path)
+
+			policy, err := Load(&config.Config{})
+			if tt.wantErr == "" {
+				if err != nil || policy == nil || policy.Path != path {
+					t.Errorf("got %v, %v; want the policy from %s", policy, err, path)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("got %v, want an error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	// A configured policy file that is missing must not lift the restrictions
+	t.Setenv(EnvVar, filepath.Join(t.TempDir(), "missing.json"))
+	if _, err := Load(&config.Config{}); err == nil {
+		t.Error("expected an error for a missing policy file")
+	}
+}
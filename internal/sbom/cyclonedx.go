@@ -0,0 +1,120 @@
+package sbom
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+type cdxBOM struct {
+	BOMFormat    string         `json:"bomFormat"`
+	SpecVersion  string         `json:"specVersion"`
+	SerialNumber string         `json:"serialNumber"`
+	Version      int            `json:"version"`
+	Metadata     cdxMetadata    `json:"metadata"`
+	Components   []cdxComponent `json:"components"`
+}
+
+type cdxMetadata struct {
+	Timestamp string   `json:"timestamp"`
+	Tools     cdxTools `json:"tools"`
+}
+
+type cdxTools struct {
+	Components []cdxComponent `json:"components"`
+}
+
+type cdxComponent struct {
+	Type               string           `json:"type"`
+	BOMRef             string           `json:"bom-ref,omitempty"`
+	Supplier           *cdxOrganization `json:"supplier,omitempty"`
+	Name               string           `json:"name"`
+	Version            string           `json:"version,omitempty"`
+	PURL               string           `json:"purl,omitempty"`
+	ExternalReferences []cdxReference   `json:"externalReferences,omitempty"`
+	Properties         []cdxProperty    `json:"properties,omitempty"`
+}
+
+type cdxOrganization struct {
+	Name string `json:"name"`
+}
+
+type cdxReference struct {
+	Type   string    `json:"type"`
+	URL    string    `json:"url"`
+	Hashes []cdxHash `json:"hashes,omitempty"`
+}
+
+type cdxHash struct {
+	Alg     string `json:"alg"`
+	Content string `json:"content"`
+}
+
+type cdxProperty struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// cdxHashAlgorithms maps checksum algorithms to CycloneDX names
+var cdxHashAlgorithms = map[string]string{"sha1": "SHA-1", "sha256": "SHA-256", "sha512": "SHA-512"}
+
+// encodeCycloneDX renders the document as CycloneDX 1.5. The archive a runtime
+// was installed from is its distribution reference, with the archive digest;
+// the remaining provenance is kept in jv:* properties.
+func encodeCycloneDX(doc Document) ([]byte, error) {
+	bom := cdxBOM{
+		BOMFormat:    "CycloneDX",
+		SpecVersion:  "1.5",
+		SerialNumber: "urn:uuid:" + newUUID(),
+		Version:      1,
+		Metadata: cdxMetadata{
+			Timestamp: doc.Created.UTC().Format(time.RFC3339),
+			Tools: cdxTools{Components: []cdxComponent{
+				{Type: "application", Name: "jv", Version: doc.ToolVersion},
+			}},
+		},
+		Components: []cdxComponent{},
+	}
+
+	for idx, runtime := range doc.Runtimes {
+		component := cdxComponent{
+			Type:    "platform",
+			BOMRef:  fmt.Sprintf("runtime-%d", idx+1),
+			Name:    runtime.name(),
+			Version: runtime.Version,
+			PURL:    runtime.purl(),
+		}
+		if runtime.Vendor != "" {
+			component.Supplier = &cdxOrganization{Name: runtime.Vendor}
+		}
+
+		source := runtime.SourceURL
+		if url := runtime.downloadURL(); url != "" {
+			reference := cdxReference{Type: "distribution", URL: url}
+			if algo, value, ok := runtime.digest(); ok && cdxHashAlgorithms[algo] != "" {
+				reference.Hashes = []cdxHash{{Alg: cdxHashAlgorithms[algo], Content: value}}
+			}
+			component.ExternalReferences = []cdxReference{reference}
+			source = "" // Already the distribution reference
+		}
+
+		for _, property := range []cdxProperty{
+			{Name: "jv:path", Value: runtime.Path},
+			{Name: "jv:arch", Value: runtime.Arch},
+			{Name: "jv:image_type", Value: runtime.ImageType},
+			{Name: "jv:vendor_build", Value: runtime.VendorBuild},
+			{Name: "jv:installed_at", Value: runtime.InstalledAt},
+			{Name: "jv:source", Value: source},
+			{Name: "jv:checksum", Value: runtime.Checksum},
+			{Name: "jv:signature", Value: runtime.Signature},
+		} {
+			if property.Value != "" {
+				component.Properties = append(component.Properties, property)
+			}
+		}
+
+		bom.Components = append(bom.Components, component)
+	}
+
+	return json.MarshalIndent(bom, "", "  ")
+}
@@ -0,0 +1,127 @@
+// Package sbom describes installed Java runtimes as a software bill of
+// materials in the CycloneDX or SPDX JSON format.
+package sbom
+
+import (
+	"crypto/rand"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Format is an SBOM document format
+type Format string
+
+const (
+	FormatCycloneDX Format = "cyclonedx" // CycloneDX 1.5 JSON
+	FormatSPDX      Format = "spdx"      // SPDX 2.3 JSON
+)
+
+// ParseFormat validates a --format value
+func ParseFormat(value string) (Format, error) {
+	switch Format(strings.ToLower(strings.TrimSpace(value))) {
+	case FormatCycloneDX:
+		return FormatCycloneDX, nil
+	case FormatSPDX:
+		return FormatSPDX, nil
+	}
+	return "", fmt.Errorf("unknown SBOM format %q (use cyclonedx or spdx)", value)
+}
+
+// Runtime is an installed Java runtime. Provenance fields are empty for JDKs
+// that jv did not install or that were installed by older versions of jv.
+type Runtime struct {
+	Vendor      string
+	Version     string // Full version, e.g. "21.0.5+11"
+	VendorBuild string // e.g. "Temurin-21.0.5+11"
+	ImageType   string // "jdk", "jre" or the image type jv recorded, e.g. "debugimage"
+	Arch        string
+	Path        string
+	InstalledAt string // RFC 3339
+
+	SourceURL string // Download URL, or the path of a local archive
+	Checksum  string // Digest of the archive, e.g. "sha256:<hex>"
+	Signature string // "verified", "unsigned" or "no-key"
+}
+
+// Document is the information shared by both formats
+type Document struct {
+	Runtimes    []Runtime
+	ToolVersion string // Version of jv
+	Created     time.Time
+}
+
+// Encode renders the document in format
+func Encode(doc Document, format Format) ([]byte, error) {
+	switch format {
+	case FormatCycloneDX:
+		return encodeCycloneDX(doc)
+	case FormatSPDX:
+		return encodeSPDX(doc)
+	}
+	return nil, fmt.Errorf("unknown SBOM format %q", format)
+}
+
+// name returns a display name such as "Eclipse Adoptium JDK"
+func (r Runtime) name() string {
+	image := strings.ToUpper(r.ImageType)
+	if image == "" {
+		image = "JDK"
+	}
+	if r.Vendor == "" {
+		return "Java " + image
+	}
+	return r.Vendor + " " + image
+}
+
+// purl returns a generic package URL for the runtime, e.g.
+// pkg:generic/eclipse-adoptium/jdk@21.0.5%2B11?arch=x64
+func (r Runtime) purl() string {
+	name := strings.ToLower(r.ImageType)
+	if name == "" {
+		name = "jdk"
+	}
+	if vendor := slug(r.Vendor); vendor != "" {
+		name = vendor + "/" + name
+	}
+	purl := fmt.Sprintf("pkg:generic/%s@%s", name, strings.ReplaceAll(url.PathEscape(r.Version), "+", "%2B"))
+	if r.Arch != "" {
+		purl += "?arch=" + url.QueryEscape(r.Arch)
+	}
+	return purl
+}
+
+// downloadURL returns SourceURL when it is a URL rather than a local path
+func (r Runtime) downloadURL() string {
+	if strings.HasPrefix(r.SourceURL, "https://") || strings.HasPrefix(r.SourceURL, "http://") {
+		return r.SourceURL
+	}
+	return ""
+}
+
+// digest splits Checksum into its algorithm and hex value
+func (r Runtime) digest() (string, string, bool) {
+	algo, value, ok := strings.Cut(r.Checksum, ":")
+	if !ok || value == "" {
+		return "", "", false
+	}
+	return strings.ToLower(algo), value, true
+}
+
+// slug lower-cases name and joins its words with dashes
+func slug(name string) string {
+	fields := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9')
+	})
+	return strings.Join(fields, "-")
+}
+
+// newUUID returns a random (version 4) UUID
+func newUUID() string {
+	var b [16]byte
+	rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
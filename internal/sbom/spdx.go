@@ -0,0 +1,136 @@
+package sbom
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+type spdxDocument struct {
+	SPDXVersion       string             `json:"spdxVersion"`
+	DataLicense       string             `json:"dataLicense"`
+	SPDXID            string             `json:"SPDXID"`
+	Name              string             `json:"name"`
+	DocumentNamespace string             `json:"documentNamespace"`
+	CreationInfo      spdxCreationInfo   `json:"creationInfo"`
+	Packages          []spdxPackage      `json:"packages"`
+	Relationships     []spdxRelationship `json:"relationships"`
+}
+
+type spdxCreationInfo struct {
+	Created  string   `json:"created"`
+	Creators []string `json:"creators"`
+}
+
+type spdxPackage struct {
+	SPDXID                string            `json:"SPDXID"`
+	Name                  string            `json:"name"`
+	VersionInfo           string            `json:"versionInfo,omitempty"`
+	Supplier              string            `json:"supplier"`
+	DownloadLocation      string            `json:"downloadLocation"`
+	FilesAnalyzed         bool              `json:"filesAnalyzed"`
+	Checksums             []spdxChecksum    `json:"checksums,omitempty"`
+	LicenseConcluded      string            `json:"licenseConcluded"`
+	LicenseDeclared       string            `json:"licenseDeclared"`
+	CopyrightText         string            `json:"copyrightText"`
+	PrimaryPackagePurpose string            `json:"primaryPackagePurpose"`
+	ExternalRefs          []spdxExternalRef `json:"externalRefs,omitempty"`
+	Comment               string            `json:"comment,omitempty"`
+}
+
+type spdxChecksum struct {
+	Algorithm     string `json:"algorithm"`
+	ChecksumValue string `json:"checksumValue"`
+}
+
+type spdxExternalRef struct {
+	ReferenceCategory string `json:"referenceCategory"`
+	ReferenceType     string `json:"referenceType"`
+	ReferenceLocator  string `json:"referenceLocator"`
+}
+
+type spdxRelationship struct {
+	SPDXElementID      string `json:"spdxElementId"`
+	RelationshipType   string `json:"relationshipType"`
+	RelatedSPDXElement string `json:"relatedSpdxElement"`
+}
+
+// spdxNoAssertion marks information jv does not know, such as licenses
+const spdxNoAssertion = "NOASSERTION"
+
+// encodeSPDX renders the document as SPDX 2.3. Each runtime is a package
+// whose checksum is that of the archive it was installed from; the remaining
+// provenance goes into the package comment.
+func encodeSPDX(doc Document) ([]byte, error) {
+	uuid := newUUID()
+	document := spdxDocument{
+		SPDXVersion:       "SPDX-2.3",
+		DataLicense:       "CC0-1.0",
+		SPDXID:            "SPDXRef-DOCUMENT",
+		Name:              "java-runtimes",
+		DocumentNamespace: "https://github.com/CostaBrosky/jv/spdxdocs/java-runtimes-" + uuid,
+		CreationInfo: spdxCreationInfo{
+			Created:  doc.Created.UTC().Format(time.RFC3339),
+			Creators: []string{"Tool: jv-" + doc.ToolVersion},
+		},
+		Packages:      []spdxPackage{},
+		Relationships: []spdxRelationship{},
+	}
+
+	for idx, runtime := range doc.Runtimes {
+		pkg := spdxPackage{
+			SPDXID:                fmt.Sprintf("SPDXRef-Runtime-%d", idx+1),
+			Name:                  runtime.name(),
+			VersionInfo:           runtime.Version,
+			Supplier:              spdxNoAssertion,
+			DownloadLocation:      spdxNoAssertion,
+			LicenseConcluded:      spdxNoAssertion,
+			LicenseDeclared:       spdxNoAssertion,
+			CopyrightText:         spdxNoAssertion,
+			PrimaryPackagePurpose: "APPLICATION",
+			ExternalRefs: []spdxExternalRef{
+				{ReferenceCategory: "PACKAGE-MANAGER", ReferenceType: "purl", ReferenceLocator: runtime.purl()},
+			},
+		}
+		if runtime.Vendor != "" {
+			pkg.Supplier = "Organization: " + runtime.Vendor
+		}
+		if url := runtime.downloadURL(); url != "" {
+			pkg.DownloadLocation = url
+		}
+		if algo, value, ok := runtime.digest(); ok {
+			pkg.Checksums = []spdxChecksum{{Algorithm: strings.ToUpper(algo), ChecksumValue: value}}
+		}
+		pkg.Comment = spdxComment(runtime)
+
+		document.Packages = append(document.Packages, pkg)
+		document.Relationships = append(document.Relationships, spdxRelationship{
+			SPDXElementID:      document.SPDXID,
+			RelationshipType:   "DESCRIBES",
+			RelatedSPDXElement: pkg.SPDXID,
+		})
+	}
+
+	return json.MarshalIndent(document, "", "  ")
+}
+
+// spdxComment lists what SPDX has no field for, e.g.
+// "path C:\...; arch x64; signature verified"
+func spdxComment(runtime Runtime) string {
+	var parts []string
+	add := func(label string, value string) {
+		if value != "" {
+			parts = append(parts, label+" "+value)
+		}
+	}
+	add("path", runtime.Path)
+	add("installed", runtime.InstalledAt)
+	add("arch", runtime.Arch)
+	add("vendor build", runtime.VendorBuild)
+	if runtime.downloadURL() == "" {
+		add("source", runtime.SourceURL)
+	}
+	add("signature", runtime.Signature)
+	return strings.Join(parts, "; ")
+}
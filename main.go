@@ -1,15 +1,31 @@
 package main
 
 import (
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
+	"jv/internal/audit"
+	"jv/internal/certs"
 	"jv/internal/config"
 	"jv/internal/env"
+	"jv/internal/hooks"
 	"jv/internal/installer"
 	"jv/internal/java"
+	"jv/internal/lifecycle"
+	"jv/internal/manifest"
+	"jv/internal/policy"
+	"jv/internal/sbom"
 	"jv/internal/theme"
 
 	"github.com/charmbracelet/huh"
@@ -63,6 +79,18 @@ func main() {
 		handleDoctor()
 	case "repair":
 		handleRepair()
+	case "cache":
+		handleCache()
+	case "catalog":
+		handleCatalog()
+	case "certs":
+		handleCerts()
+	case "audit":
+		handleAudit()
+	case "sbom":
+		handleSbom()
+	case "verify":
+		handleVerify()
 	case "version", "-v", "--version":
 		printVersion()
 	case "help", "-h", "--help":
@@ -115,6 +143,9 @@ func handleList() {
 	fmt.Println(titleStyle.Render("Available Java Versions:"))
 	fmt.Println()
 
+	hostArch := java.HostArch()
+	releases := lifecycle.Load(cfg.GetCatalogDir())
+	now := time.Now()
 	for _, v := range versions {
 		marker := "  "
 		versionStr := v.Version
@@ -142,6 +173,17 @@ func handleList() {
 			}
 		}
 
+		// JREs have no compiler
+		if v.IsJRE {
+			versionStr += " " + theme.WarningStyle.Render("JRE")
+		}
+		if v.Arch != "" && v.Arch != hostArch {
+			versionStr += " " + theme.WarningStyle.Render(v.Arch)
+		}
+		if cycle, ok := javaLifecycle(releases, cfg, v); ok && cycle.IsEOL(now) {
+			versionStr += " " + theme.ErrorStyle.Render("EOL")
+		}
+
 		// Align version column to width 15 considering visual width
 		visW := lipgloss.Width(versionStr)
 		pad := 0
@@ -165,6 +207,33 @@ func handleList() {
 	}
 }
 
+// javaLifecycle returns the lifecycle of the feature release of v, for the
+// vendor named in its release file or the distributor it was installed from
+func javaLifecycle(releases *lifecycle.Dataset, cfg *config.Config, v java.Version) (lifecycle.Cycle, bool) {
+	return releases.Lookup(javaVendor(cfg, v), lifecycle.Major(v.Version))
+}
+
+// javaVendor returns the vendor named in the release file of v, or the
+// distributor it was installed from; "" if neither is known
+func javaVendor(cfg *config.Config, v java.Version) string {
+	if release, err := java.ReadReleaseFile(v.Path); err == nil && release["IMPLEMENTOR"] != "" {
+		return release["IMPLEMENTOR"]
+	}
+	if jdk := cfg.GetInstalledJDK(v.Path); jdk != nil {
+		return jdk.Distributor
+	}
+	return ""
+}
+
+// javaFullVersion returns the exact version of v from its release file, which
+// java -version output may abbreviate
+func javaFullVersion(v java.Version) string {
+	if release, err := java.ReadReleaseFile(v.Path); err == nil && release["JAVA_VERSION"] != "" {
+		return release["JAVA_VERSION"]
+	}
+	return v.Version
+}
+
 func handleUse() {
 	detector := java.NewDetector()
 	versions, err := detector.FindAll()
@@ -180,6 +249,10 @@ func handleUse() {
 	}
 
 	var target *java.Version
+	current, _ := env.GetJavaHome()
+	if current == "" {
+		current = os.Getenv("JAVA_HOME")
+	}
 
 	// Interactive mode if no version specified
 	if len(os.Args) < 3 {
@@ -189,10 +262,6 @@ func handleUse() {
 			os.Exit(1)
 		}
 		// If selected is already current, no-op
-		current, _ := env.GetJavaHome()
-		if current == "" {
-			current = os.Getenv("JAVA_HOME")
-		}
 		if strings.EqualFold(selected.Path, current) {
 			fmt.Println(infoStyle.Render(fmt.Sprintf("Already using Java %s. No changes needed.", selected.Version)))
 			os.Exit(0)
@@ -215,10 +284,6 @@ func handleUse() {
 		}
 
 		// If specified version is already current, no-op
-		current, _ := env.GetJavaHome()
-		if current == "" {
-			current = os.Getenv("JAVA_HOME")
-		}
 		if strings.EqualFold(target.Path, current) {
 			fmt.Println(infoStyle.Render(fmt.Sprintf("Already using Java %s. No changes needed.", target.Version)))
 			os.Exit(0)
@@ -235,19 +300,7 @@ func handleUse() {
 		os.Exit(0)
 	}
 
-	fmt.Println(infoStyle.Render(fmt.Sprintf("Switching to Java %s...", target.Version)))
-
-	if err := env.SetJavaHome(target.Path); err != nil {
-		fmt.Println(errorStyle.Render(fmt.Sprintf("Error: %v", err)))
-		fmt.Println()
-		fmt.Println(warningStyle.Render("Note: This command requires administrator privileges."))
-		fmt.Println(theme.Faint.Render("Please run your terminal as Administrator and try again."))
-		os.Exit(1)
-	}
-
-	fmt.Println(successStyle.Render("✓ Successfully updated JAVA_HOME!"))
-	fmt.Println()
-	fmt.Println(lipgloss.NewStyle().Faint(true).Render("Note: You may need to restart your terminal or applications for changes to take effect."))
+	switchJavaHome(target, current)
 }
 
 func handleCurrent() {
@@ -646,21 +699,100 @@ func handleListPaths() {
 }
 
 func handleInstall() {
+	fs := flag.NewFlagSet("install", flag.ContinueOnError)
+	requireSignature := fs.Bool("require-signature", false, "refuse archives without a valid vendor signature")
+	userScope := fs.Bool("user", false, "install for the current user only (non-interactive mode)")
+	image := fs.String("image", "", "image type: jdk, jre, debugimage, staticlibs or sources")
+	arch := fs.String("arch", "", "architecture to install, e.g. x64, aarch64, ppc64le (default: host)")
+	fromFile := fs.String("from-file", "", "install from a local archive instead of downloading")
+	checksum := fs.String("checksum", "", "expected checksum of --from-file, e.g. sha256:<hex>")
+	progress := fs.String("progress", "auto", "progress output: auto, tty, plain (log lines) or none")
+	dryRun := fs.Bool("dry-run", false, "show the install plan and disk space check without installing")
+	specs, err := parseFlags(fs, os.Args[2:])
+	if err != nil {
+		os.Exit(1)
+	}
+
+	progressMode, err := installer.ParseProgressMode(*progress)
+	if err != nil {
+		fmt.Println(errorStyle.Render("Error: " + err.Error()))
+		os.Exit(1)
+	}
+	installer.SetProgressMode(progressMode)
+
+	var imageType installer.ImageType
+	if *image != "" {
+		var err error
+		if imageType, err = installer.ParseImageType(*image); err != nil {
+			fmt.Println(errorStyle.Render("Error: " + err.Error()))
+			os.Exit(1)
+		}
+	}
+
 	// Check admin privileges
 	isAdmin := env.IsAdmin()
 
 	// Create installer
-	inst, err := installer.NewInstaller(isAdmin)
+	inst, err := installer.NewInstaller(isAdmin, installer.Options{
+		RequireSignature: *requireSignature,
+		ImageType:        imageType,
+		Arch:             *arch,
+		DryRun:           *dryRun,
+	})
 	if err != nil {
 		fmt.Printf("Error: %v\n", err)
 		os.Exit(1)
 	}
 
-	// Run interactive installation
-	if err := inst.Run(); err != nil {
-		fmt.Printf("Error: %v\n", err)
+	scope := "user"
+	if isAdmin && !*userScope {
+		scope = "system"
+	}
+
+	// Ctrl+C aborts the install instead of killing jv halfway through
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	// Offline install from an archive transferred by other means
+	if *fromFile != "" {
+		if err := inst.RunFromFile(ctx, *fromFile, *checksum, scope); err != nil {
+			exitInstallError(err)
+		}
+		return
+	}
+	if *checksum != "" {
+		fmt.Println(errorStyle.Render("Error: --checksum is only used with --from-file"))
 		os.Exit(1)
 	}
+
+	// Versions on the command line (e.g. "21" or "17.0.8+7") skip the menus
+	if len(specs) > 0 {
+		if err := inst.RunNonInteractive(ctx, specs, scope); err != nil {
+			exitInstallError(err)
+		}
+		return
+	}
+
+	// Run interactive installation
+	if err := inst.Run(ctx); err != nil {
+		exitInstallError(err)
+	}
+}
+
+// exitInstallError reports a failed install and exits; an interrupted install
+// exits with the conventional status for SIGINT
+func exitInstallError(err error) {
+	var partial *installer.CancelledInstallError
+	if errors.As(err, &partial) {
+		fmt.Println(theme.WarningMessage(fmt.Sprintf("Installation cancelled; Java %s stayed installed and was recorded", strings.Join(partial.Installed, ", "))))
+		os.Exit(130)
+	}
+	if errors.Is(err, context.Canceled) {
+		fmt.Println(theme.WarningMessage("Installation cancelled, configuration left unchanged"))
+		os.Exit(130)
+	}
+	fmt.Printf("Error: %v\n", err)
+	os.Exit(1)
 }
 
 func handleSwitch() {
@@ -705,6 +837,41 @@ func handleSwitch() {
 		os.Exit(0)
 	}
 
+	switchJavaHome(target, current)
+}
+
+// switchJavaHome points JAVA_HOME at target and exits on failure. The
+// pre_switch and post_switch hooks run around the change; a fatal pre_switch
+// hook leaves JAVA_HOME untouched.
+func switchJavaHome(target *java.Version, previous string) {
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Println(warningStyle.Render("Cannot load config, switch hooks are skipped: " + err.Error()))
+		cfg = &config.Config{}
+	}
+	runner := hooks.NewRunner(cfg)
+
+	pol, err := policy.Load(cfg)
+	if err == nil {
+		err = pol.Check(javaVendor(cfg, *target), javaFullVersion(*target))
+	}
+	if err != nil {
+		fmt.Println(errorStyle.Render(fmt.Sprintf("Error: %v", err)))
+		fmt.Println(theme.Faint.Render("JAVA_HOME was not changed."))
+		os.Exit(1)
+	}
+	hookTarget := hooks.TargetFor(target.Path, target.Version)
+	hookTarget.PreviousPath = previous
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if err := runner.Run(ctx, hooks.PreSwitch, hookTarget); err != nil {
+		fmt.Println(errorStyle.Render(fmt.Sprintf("Error: %v", err)))
+		fmt.Println(theme.Faint.Render("JAVA_HOME was not changed."))
+		os.Exit(1)
+	}
+
 	fmt.Println(infoStyle.Render(fmt.Sprintf("Switching to Java %s...", target.Version)))
 
 	if err := env.SetJavaHome(target.Path); err != nil {
@@ -716,10 +883,49 @@ func handleSwitch() {
 	}
 
 	fmt.Println(successStyle.Render("✓ Successfully updated JAVA_HOME!"))
+
+	if err := runner.Run(ctx, hooks.PostSwitch, hookTarget); err != nil {
+		fmt.Println(errorStyle.Render(fmt.Sprintf("Error: %v", err)))
+		os.Exit(1)
+	}
+
 	fmt.Println()
 	fmt.Println(lipgloss.NewStyle().Faint(true).Render("Note: You may need to restart your terminal or applications for changes to take effect."))
 }
 
+// repairJavaHome points JAVA_HOME at target for jv repair. Like switchJavaHome
+// it enforces the policy and runs the switch hooks, but returns errors so that
+// the remaining repairs still run.
+func repairJavaHome(target *java.Version, previous string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		cfg = &config.Config{}
+	}
+
+	pol, err := policy.Load(cfg)
+	if err != nil {
+		return err
+	}
+	if err := pol.Check(javaVendor(cfg, *target), javaFullVersion(*target)); err != nil {
+		return err
+	}
+
+	hookTarget := hooks.TargetFor(target.Path, target.Version)
+	hookTarget.PreviousPath = previous
+	runner := hooks.NewRunner(cfg)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if err := runner.Run(ctx, hooks.PreSwitch, hookTarget); err != nil {
+		return err
+	}
+	if err := env.SetJavaHome(target.Path); err != nil {
+		return err
+	}
+	return runner.Run(ctx, hooks.PostSwitch, hookTarget)
+}
+
 func handleDoctor() {
 	fmt.Println(titleStyle.Render("Java Version Switcher - System Diagnostics"))
 	fmt.Println()
@@ -741,6 +947,19 @@ func handleDoctor() {
 		issues = append(issues, "JAVA_HOME is not set")
 	} else if detector.IsValidJavaPath(currentJavaHome) {
 		fmt.Printf("  %s %s\n", theme.SuccessMessage("JAVA_HOME is set and valid:"), theme.PathStyle.Render(currentJavaHome))
+		if arch, hostArch := detector.GetArch(currentJavaHome), java.HostArch(); arch != "" && arch != hostArch {
+			fmt.Println("  " + theme.WarningMessage(fmt.Sprintf("JAVA_HOME is a %s build but this machine is %s (runs emulated, if at all)", arch, hostArch)))
+			warnings = append(warnings, fmt.Sprintf("JAVA_HOME architecture (%s) does not match the host (%s)", arch, hostArch))
+		}
+		if cfg, err := config.Load(); err == nil {
+			current := java.Version{Version: detector.GetVersion(currentJavaHome), Path: currentJavaHome}
+			cycle, ok := javaLifecycle(lifecycle.Load(cfg.GetCatalogDir()), cfg, current)
+			if ok && cycle.IsEOL(time.Now()) {
+				msg := fmt.Sprintf("JAVA_HOME points at Java %s, which reached end of life on %s (%s)", cycle.Major, cycle.EOL, cycle.Vendor)
+				fmt.Println("  " + theme.WarningMessage(msg))
+				warnings = append(warnings, msg+". Move to a supported release such as the current LTS.")
+			}
+		}
 	} else {
 		fmt.Printf("  %s %s\n", theme.ErrorStyle.Render("✗ JAVA_HOME is set but invalid:"), theme.PathStyle.Render(currentJavaHome))
 		issues = append(issues, fmt.Sprintf("JAVA_HOME points to invalid location: %s", currentJavaHome))
@@ -859,10 +1078,49 @@ func handleDoctor() {
 		if len(cfg.InstalledJDKs) > 0 {
 			fmt.Println("  " + theme.SuccessMessage(fmt.Sprintf("Tracked JDKs: %d", len(cfg.InstalledJDKs))))
 		}
+		if len(cfg.Hooks) > 0 {
+			problems := hooks.Validate(cfg)
+			for _, problem := range problems {
+				fmt.Println("  " + theme.WarningMessage("Hooks: "+problem))
+				warnings = append(warnings, "Hook configuration: "+problem)
+			}
+			if len(problems) == 0 {
+				fmt.Println("  " + theme.SuccessMessage(fmt.Sprintf("Hook events configured: %d", len(cfg.Hooks))))
+			}
+		}
 	}
 	fmt.Println()
 
-	// 5. Check administrator privileges
+	// 5. Check organization policy
+	if cfg != nil && policy.PathFor(cfg) != "" {
+		fmt.Println(theme.LabelStyle.Render("Checking policy..."))
+		pol, err := policy.Load(cfg)
+		if err != nil {
+			fmt.Println("  " + theme.ErrorMessage(err.Error()))
+			issues = append(issues, fmt.Sprintf("Policy cannot be read, installs and switches are blocked: %v", err))
+		} else {
+			fmt.Printf("  %s %s\n", theme.SuccessMessage("Policy:"), theme.PathStyle.Render(pol.Path))
+			if rules := pol.Rules(); len(rules) > 0 {
+				fmt.Println("  " + theme.Faint.Render("Rules: "+strings.Join(rules, ", ")))
+			}
+			for _, v := range versions {
+				violation := pol.Check(javaVendor(cfg, v), javaFullVersion(v))
+				if violation == nil {
+					continue
+				}
+				if strings.EqualFold(v.Path, currentJavaHome) {
+					fmt.Println("  " + theme.ErrorMessage(fmt.Sprintf("JAVA_HOME (Java %s): %v", v.Version, violation)))
+					issues = append(issues, fmt.Sprintf("JAVA_HOME points at a JDK the policy forbids: %v", violation))
+				} else {
+					fmt.Println("  " + theme.WarningMessage(fmt.Sprintf("Java %s at %s: %v", v.Version, v.Path, violation)))
+					warnings = append(warnings, fmt.Sprintf("Java %s at %s is not allowed by the policy", v.Version, v.Path))
+				}
+			}
+		}
+		fmt.Println()
+	}
+
+	// 6. Check administrator privileges
 	fmt.Println(theme.LabelStyle.Render("Checking privileges..."))
 	isAdmin := env.IsAdmin()
 	if isAdmin {
@@ -873,7 +1131,7 @@ func handleDoctor() {
 	}
 	fmt.Println()
 
-	// 6. Check if jv.exe is accessible
+	// 7. Check if jv.exe is accessible
 	fmt.Println(theme.LabelStyle.Render("Checking jv tool..."))
 	if _, err := os.Executable(); err != nil {
 		fmt.Println("  " + theme.WarningMessage("Could not determine jv executable path"))
@@ -921,133 +1179,1076 @@ func handleDoctor() {
 	fmt.Println(boxStyle.Render(summaryContent))
 }
 
-type RepairIssue struct {
-	ID            string
-	Description   string
-	RequiresAdmin bool
-	CanFix        bool
-}
+func handleCache() {
+	if len(os.Args) < 3 {
+		fmt.Println(errorStyle.Render("Usage: jv cache <list|prune|clean>"))
+		fmt.Println(infoStyle.Render("Example: jv cache prune --older-than 30d"))
+		os.Exit(1)
+	}
 
-func handleRepair() {
-	// Themed header
-	header := theme.Title.Padding(0, 2).Render("Java Version Switcher - Auto Repair")
-	fmt.Println(theme.TitleBox.Render(header))
-	fmt.Println()
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Println(errorStyle.Render("Error loading config: " + err.Error()))
+		os.Exit(1)
+	}
+	cache := installer.NewCache(cfg.GetCacheDir())
 
-	isAdmin := env.IsAdmin()
-	if !isAdmin {
-		fmt.Println(theme.WarningMessage("Not running as Administrator"))
-		fmt.Println(theme.Faint.Render("   Some repairs require administrator privileges."))
-		fmt.Println()
+	switch os.Args[2] {
+	case "list":
+		handleCacheList(cache)
+	case "prune":
+		handleCachePrune(cache)
+	case "clean":
+		handleCacheClean(cache)
+	default:
+		fmt.Printf("Unknown cache command: %s\n", os.Args[2])
+		fmt.Println(infoStyle.Render("Usage: jv cache <list|prune|clean>"))
+		os.Exit(1)
 	}
+}
 
-	detector := java.NewDetector()
+func handleCatalog() {
+	if len(os.Args) < 3 || os.Args[2] != "refresh" {
+		fmt.Println(errorStyle.Render("Usage: jv catalog refresh [--arch <arch>] [--image <type>] [--progress <mode>]"))
+		os.Exit(1)
+	}
 
-	// Step 1: Detect all issues
-	fmt.Println(theme.LabelStyle.Render("Scanning for issues..."))
-	fmt.Println()
+	fs := flag.NewFlagSet("catalog refresh", flag.ContinueOnError)
+	arch := fs.String("arch", java.HostArch(), "architecture to refresh download information for")
+	image := fs.String("image", "jdk", "image type to refresh download information for")
+	progress := fs.String("progress", "auto", "progress output: auto, tty, plain or none")
+	if err := fs.Parse(os.Args[3:]); err != nil {
+		os.Exit(1)
+	}
 
-	// Find Java installations first
-	versions, err := detector.FindAll()
+	progressMode, err := installer.ParseProgressMode(*progress)
 	if err != nil {
-		fmt.Println(theme.ErrorMessage(fmt.Sprintf("Error finding Java versions: %v", err)))
+		fmt.Println(errorStyle.Render("Error: " + err.Error()))
 		os.Exit(1)
 	}
+	installer.SetProgressMode(progressMode)
 
-	if len(versions) == 0 {
-		fmt.Println(theme.ErrorMessage("No Java installations found."))
-		fmt.Println(theme.Faint.Render("Please install Java first: jv install"))
+	imageType, err := installer.ParseImageType(*image)
+	if err != nil {
+		fmt.Println(errorStyle.Render("Error: " + err.Error()))
 		os.Exit(1)
 	}
 
-	// Detect all issues
-	issues := []RepairIssue{}
-	currentJavaHome := os.Getenv("JAVA_HOME")
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Println(errorStyle.Render("Error loading config: " + err.Error()))
+		os.Exit(1)
+	}
 
-	// Issue 1: JAVA_HOME not set or invalid
-	if currentJavaHome == "" {
-		issues = append(issues, RepairIssue{
-			ID:            "java_home_not_set",
-			Description:   "JAVA_HOME is not set",
-			RequiresAdmin: true,
-			CanFix:        isAdmin,
-		})
-	} else if !detector.IsValidJavaPath(currentJavaHome) {
-		issues = append(issues, RepairIssue{
-			ID:            "java_home_invalid",
-			Description:   fmt.Sprintf("JAVA_HOME is invalid: %s", currentJavaHome),
-			RequiresAdmin: true,
-			CanFix:        isAdmin,
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	failed := false
+	for _, distributor := range installer.CatalogDistributors(cfg) {
+		var refreshed int
+		var refreshErr error
+		spinnerErr := installer.WithSpinner(ctx, fmt.Sprintf("Refreshing %s catalog...", distributor.Name()), func(ctx context.Context) error {
+			refreshed, refreshErr = distributor.Refresh(ctx, *arch, imageType)
+			return nil
 		})
-	}
+		if errors.Is(spinnerErr, context.Canceled) {
+			fmt.Println(theme.WarningMessage("Refresh cancelled"))
+			os.Exit(130)
+		}
+		if spinnerErr != nil {
+			fmt.Println(errorStyle.Render("Error: " + spinnerErr.Error()))
+			os.Exit(1)
+		}
 
-	// Issue 2: PATH doesn't contain %JAVA_HOME%\bin (check resolved <JAVA_HOME>\bin exactly)
-	pathEnv := os.Getenv("Path")
-	hasJavaHomeInPath := false
-	if currentJavaHome != "" {
-		expectedBin := strings.ToLower(strings.TrimRight(filepath.Join(currentJavaHome, "bin"), "\\"))
-		for _, entry := range strings.Split(pathEnv, ";") {
-			e := strings.TrimSpace(strings.Trim(entry, "\""))
-			if e == "" {
-				continue
-			}
-			eLower := strings.ToLower(strings.TrimRight(e, "\\"))
-			if eLower == expectedBin {
-				hasJavaHomeInPath = true
-				break
-			}
+		if refreshed == 0 && refreshErr != nil {
+			fmt.Println(theme.ErrorMessage(fmt.Sprintf("%s: %v", distributor.Name(), refreshErr)))
+			failed = true
+			continue
+		}
+		fmt.Println(theme.SuccessMessage(fmt.Sprintf("%s: %d versions refreshed (%s, %s)", distributor.Name(), refreshed, *arch, imageType)))
+		if refreshErr != nil {
+			fmt.Println("  " + theme.WarningMessage(refreshErr.Error()))
 		}
-	}
-	if !hasJavaHomeInPath && currentJavaHome != "" {
-		issues = append(issues, RepairIssue{
-			ID:            "path_missing_java_home",
-			Description:   "%JAVA_HOME%\\bin is not in PATH",
-			RequiresAdmin: true,
-			CanFix:        isAdmin,
-		})
 	}
 
-	// Issue 3: Config file problems
-	if _, err := config.Load(); err != nil {
-		issues = append(issues, RepairIssue{
-			ID:            "config_error",
-			Description:   fmt.Sprintf("Configuration file error: %v", err),
-			RequiresAdmin: false,
-			CanFix:        true,
-		})
+	var refreshed int
+	var refreshErr error
+	spinnerErr := installer.WithSpinner(ctx, "Refreshing end-of-life data...", func(ctx context.Context) error {
+		refreshed, refreshErr = lifecycle.Refresh(ctx, cfg.GetCatalogDir())
+		return nil
+	})
+	if errors.Is(spinnerErr, context.Canceled) || errors.Is(refreshErr, context.Canceled) {
+		fmt.Println(theme.WarningMessage("Refresh cancelled"))
+		os.Exit(130)
 	}
-
-	// No issues found
-	if len(issues) == 0 {
-		fmt.Println(successStyle.Render("✓ No issues found - your environment is properly configured!"))
-		return
+	if spinnerErr != nil {
+		fmt.Println(errorStyle.Render("Error: " + spinnerErr.Error()))
+		os.Exit(1)
 	}
-
-	// Show issues
-	fmt.Printf("%s %d issue(s):\n", theme.LabelStyle.Render("Found"), len(issues))
-	for i, issue := range issues {
-		adminMarker := ""
-		if issue.RequiresAdmin {
-			adminMarker = " [Requires Admin]"
-		}
-		if !issue.CanFix {
-			adminMarker += " [Cannot Fix]"
+	if refreshed == 0 {
+		// The dataset built into jv keeps working, so this is not a failure
+		fmt.Println(theme.WarningMessage(fmt.Sprintf("End-of-life data not refreshed: %v", refreshErr)))
+	} else {
+		fmt.Println(theme.SuccessMessage(fmt.Sprintf("End-of-life data: %d vendors refreshed", refreshed)))
+		if refreshErr != nil {
+			fmt.Println("  " + theme.WarningMessage(refreshErr.Error()))
 		}
-		fmt.Printf("  %d. %s%s\n", i+1, theme.WarningStyle.Render(issue.Description), adminMarker)
 	}
-	fmt.Println()
 
-	// Filter fixable issues
-	fixableIssues := []RepairIssue{}
-	for _, issue := range issues {
-		if issue.CanFix {
-			fixableIssues = append(fixableIssues, issue)
-		}
+	if failed {
+		os.Exit(1)
 	}
+}
 
-	if len(fixableIssues) == 0 {
-		fmt.Println(theme.ErrorMessage("No fixable issues (some require administrator privileges)"))
-		fmt.Println(theme.Faint.Render("  Run as administrator to fix all issues"))
+func handleCacheList(cache *installer.Cache) {
+	entries, err := cache.List()
+	if err != nil {
+		fmt.Println(errorStyle.Render("Error reading cache: " + err.Error()))
+		os.Exit(1)
+	}
+
+	fmt.Println(titleStyle.Render("Download Cache"))
+	fmt.Println()
+	fmt.Printf("%s %s\n", theme.LabelStyle.Render("Location:"), theme.PathStyle.Render(cache.Dir()))
+	fmt.Println()
+
+	if len(entries) == 0 {
+		fmt.Println(infoStyle.Render("The download cache is empty."))
+		return
+	}
+
+	headerStyle := theme.TableHeader
+	cellStyle := theme.TableCell
+	tableStyle := theme.TableStyle
+
+	var rows []string
+	rows = append(rows, lipgloss.JoinHorizontal(lipgloss.Left,
+		headerStyle.Width(58).Render("Archive"),
+		headerStyle.Width(12).Render("Size"),
+		headerStyle.Render("Last used"),
+	))
+
+	var total int64
+	for _, e := range entries {
+		total += e.Size
+		rows = append(rows, lipgloss.JoinHorizontal(lipgloss.Left,
+			cellStyle.Width(58).Render(e.FileName),
+			cellStyle.Width(12).Render(installer.FormatSize(e.Size)),
+			cellStyle.Render(e.LastUsed.Format("2006-01-02 15:04")),
+		))
+	}
+
+	table := lipgloss.JoinVertical(lipgloss.Left, rows...)
+	fmt.Println(tableStyle.Render(table))
+	fmt.Println()
+	fmt.Printf("%s %d archive(s), %s\n", theme.LabelStyle.Render("Total:"), len(entries), installer.FormatSize(total))
+}
+
+func handleCachePrune(cache *installer.Cache) {
+	fs := flag.NewFlagSet("cache prune", flag.ContinueOnError)
+	olderThan := fs.String("older-than", "30d", "remove archives not used within this age (e.g. 12h, 30d, 8w)")
+	if err := fs.Parse(os.Args[3:]); err != nil {
+		os.Exit(1)
+	}
+
+	age, err := parseAge(*olderThan)
+	if err != nil {
+		fmt.Println(errorStyle.Render(fmt.Sprintf("Invalid --older-than value: %v", err)))
+		os.Exit(1)
+	}
+
+	removed, err := cache.Prune(age)
+	if err != nil {
+		fmt.Println(errorStyle.Render("Error pruning cache: " + err.Error()))
+		os.Exit(1)
+	}
+
+	if len(removed) == 0 {
+		fmt.Println(infoStyle.Render(fmt.Sprintf("No cached archives older than %s.", *olderThan)))
+		return
+	}
+
+	var freed int64
+	for _, e := range removed {
+		freed += e.Size
+		fmt.Println("  " + theme.Faint.Render("removed ") + e.FileName)
+	}
+	fmt.Println(theme.SuccessMessage(fmt.Sprintf("Pruned %d archive(s), freed %s", len(removed), installer.FormatSize(freed))))
+}
+
+func handleCacheClean(cache *installer.Cache) {
+	confirmed, err := confirmAction(
+		"Remove all cached downloads?",
+		fmt.Sprintf("Path: %s", cache.Dir()),
+	)
+	if err != nil || !confirmed {
+		fmt.Println(warningStyle.Render("Operation cancelled."))
+		return
+	}
+
+	freed, err := cache.Clean()
+	if err != nil {
+		fmt.Println(errorStyle.Render("Error cleaning cache: " + err.Error()))
+		os.Exit(1)
+	}
+
+	fmt.Println(theme.SuccessMessage(fmt.Sprintf("Cache cleaned, freed %s", installer.FormatSize(freed))))
+}
+
+// parseFlags parses args like fs.Parse, but also accepts flags after the
+// positional arguments, as in "jv certs import corp.pem --all". Everything
+// after "--" is positional. It returns the positional arguments.
+func parseFlags(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		rest := fs.Args()
+		if consumed := len(args) - len(rest); consumed > 0 && args[consumed-1] == "--" {
+			return append(positional, rest...), nil
+		}
+		if len(rest) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, rest[0])
+		args = rest[1:]
+	}
+}
+
+// parseAge parses durations like "12h", "30d" or "8w"
+func parseAge(value string) (time.Duration, error) {
+	value = strings.TrimSpace(strings.ToLower(value))
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if n, found := strings.CutSuffix(value, suffix); found {
+			count, err := strconv.Atoi(n)
+			if err != nil || count < 0 {
+				return 0, fmt.Errorf("invalid age %q", value)
+			}
+			return time.Duration(count) * unit, nil
+		}
+	}
+	return time.ParseDuration(value)
+}
+
+type RepairIssue struct {
+	ID            string
+	Description   string
+	RequiresAdmin bool
+	CanFix        bool
+}
+
+func handleCerts() {
+	if len(os.Args) < 3 {
+		fmt.Println(errorStyle.Render("Usage: jv certs <import|list>"))
+		fmt.Println(infoStyle.Render("Example: jv certs import --all corporate-root.pem"))
+		os.Exit(1)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Println(errorStyle.Render("Error loading config: " + err.Error()))
+		os.Exit(1)
+	}
+
+	switch os.Args[2] {
+	case "import":
+		handleCertsImport(cfg)
+	case "list":
+		handleCertsList(cfg)
+	default:
+		fmt.Printf("Unknown certs command: %s\n", os.Args[2])
+		fmt.Println(infoStyle.Render("Usage: jv certs <import|list>"))
+		os.Exit(1)
+	}
+}
+
+func handleCertsImport(cfg *config.Config) {
+	fs := flag.NewFlagSet("certs import", flag.ContinueOnError)
+	all := fs.Bool("all", false, "import into every detected Java installation")
+	args, err := parseFlags(fs, os.Args[3:])
+	if err != nil {
+		os.Exit(1)
+	}
+	if len(args) < 1 || len(args) > 2 || (*all && len(args) == 2) {
+		fmt.Println(errorStyle.Render("Usage: jv certs import [--all] <pem-file> [version]"))
+		fmt.Println(infoStyle.Render("Without --all or a version the certificates go into the current JAVA_HOME."))
+		os.Exit(1)
+	}
+
+	certificates, err := certs.ReadPEMFile(args[0])
+	if err != nil {
+		fmt.Println(errorStyle.Render("Error: " + err.Error()))
+		os.Exit(1)
+	}
+
+	spec := ""
+	if len(args) == 2 {
+		spec = args[1]
+	}
+	targets, err := javaTargets(*all, spec)
+	if err != nil {
+		fmt.Println(errorStyle.Render("Error: " + err.Error()))
+		os.Exit(1)
+	}
+
+	failed := false
+	for _, target := range targets {
+		label := fmt.Sprintf("Java %s (%s)", target.Version, target.Path)
+		added, err := certs.Import(target.Path, certificates, cfg.GetTruststorePassword())
+		switch {
+		case err != nil:
+			fmt.Println(theme.ErrorMessage(fmt.Sprintf("%s: %v", label, err)))
+			failed = true
+		case added == 0:
+			fmt.Println(theme.InfoMessage(fmt.Sprintf("%s: already trusts all %d certificate(s)", label, len(certificates))))
+		default:
+			fmt.Println(theme.SuccessMessage(fmt.Sprintf("%s: imported %d certificate(s)", label, added)))
+			refreshTruststoreManifest(cfg, target.Path)
+		}
+	}
+
+	if failed {
+		fmt.Println()
+		fmt.Println(theme.Faint.Render("Note: truststores of system-wide installations can only be changed as Administrator."))
+		os.Exit(1)
+	}
+}
+
+func handleCertsList(cfg *config.Config) {
+	if len(os.Args) > 4 {
+		fmt.Println(errorStyle.Render("Usage: jv certs list [version]"))
+		os.Exit(1)
+	}
+	spec := ""
+	if len(os.Args) == 4 {
+		spec = os.Args[3]
+	}
+
+	targets, err := javaTargets(false, spec)
+	if err != nil {
+		fmt.Println(errorStyle.Render("Error: " + err.Error()))
+		os.Exit(1)
+	}
+
+	// Certificates of ca_bundle are highlighted, and reported when missing
+	var bundle []*x509.Certificate
+	if cfg.CABundle != "" {
+		if bundle, err = certs.ReadPEMFile(cfg.CABundle); err != nil {
+			fmt.Println(theme.WarningMessage("Cannot read ca_bundle: " + err.Error()))
+			fmt.Println()
+		}
+	}
+
+	headerStyle := theme.TableHeader
+	cellStyle := theme.TableCell
+	tableStyle := theme.TableStyle
+
+	for _, target := range targets {
+		fmt.Println(titleStyle.Render(fmt.Sprintf("Java %s", target.Version)))
+		fmt.Println()
+
+		path, err := certs.CACertsPath(target.Path)
+		if err != nil {
+			fmt.Println(theme.ErrorMessage(err.Error()))
+			fmt.Println()
+			continue
+		}
+		ks, err := certs.Load(path, cfg.GetTruststorePassword())
+		if err != nil {
+			fmt.Println(theme.ErrorMessage(fmt.Sprintf("Cannot read %s: %v", path, err)))
+			fmt.Println()
+			continue
+		}
+
+		fmt.Printf("%s %s\n", theme.LabelStyle.Render("Truststore:"), theme.PathStyle.Render(path))
+		fmt.Printf("%s %s\n", theme.LabelStyle.Render("Format:"), theme.ValueStyle.Render(string(ks.Format)))
+		fmt.Println()
+
+		entries := append([]certs.Entry(nil), ks.Entries...)
+		sort.Slice(entries, func(a, b int) bool { return entries[a].Alias < entries[b].Alias })
+
+		var rows []string
+		rows = append(rows, lipgloss.JoinHorizontal(lipgloss.Left,
+			headerStyle.Width(44).Render("Alias"),
+			headerStyle.Width(44).Render("Subject"),
+			headerStyle.Render("Expires"),
+		))
+		now := time.Now()
+		for _, e := range entries {
+			expires := e.Certificate.NotAfter.Format("2006-01-02")
+			if e.Certificate.NotAfter.Before(now) {
+				expires = errorStyle.Render(expires + " (expired)")
+			}
+			alias := e.Alias
+			for _, cert := range bundle {
+				if cert.Equal(e.Certificate) {
+					alias = currentStyle.Render(alias + " (ca_bundle)")
+					break
+				}
+			}
+			rows = append(rows, lipgloss.JoinHorizontal(lipgloss.Left,
+				cellStyle.Width(44).Render(alias),
+				cellStyle.Width(44).Render(certificateName(e.Certificate)),
+				cellStyle.Render(expires),
+			))
+		}
+		fmt.Println(tableStyle.Render(lipgloss.JoinVertical(lipgloss.Left, rows...)))
+		fmt.Println()
+		fmt.Printf("%s %d trusted certificate(s)\n", theme.LabelStyle.Render("Total:"), len(entries))
+		if sealed := ks.Sealed(); sealed > 0 {
+			fmt.Println(theme.Faint.Render(fmt.Sprintf("%d encrypted section(s) of the keystore cannot be listed", sealed)))
+		}
+
+		missing := 0
+		for _, cert := range bundle {
+			if !ks.Contains(cert) {
+				missing++
+			}
+		}
+		if missing > 0 {
+			fmt.Println(theme.WarningMessage(fmt.Sprintf("%d certificate(s) from ca_bundle missing", missing)))
+			fmt.Println(theme.Faint.Render(fmt.Sprintf("  Run 'jv certs import %s %s' to add them", cfg.CABundle, target.Version)))
+		}
+		fmt.Println()
+	}
+}
+
+// refreshTruststoreManifest records a truststore changed by certs import in the
+// JDK's manifest, so that jv verify does not report it as modified
+func refreshTruststoreManifest(cfg *config.Config, jdkPath string) {
+	m, err := manifest.Load(cfg.GetManifestDir(), jdkPath)
+	if err != nil {
+		return
+	}
+	path, err := certs.CACertsPath(jdkPath)
+	if err != nil {
+		return
+	}
+	if err := m.Refresh(path); err == nil {
+		m.Save(cfg.GetManifestDir())
+	}
+}
+
+// javaTargets returns the Java installations a certs or verify command applies to:
+// all detected ones, those whose version contains spec, or the current JAVA_HOME
+func javaTargets(all bool, spec string) ([]java.Version, error) {
+	detector := java.NewDetector()
+
+	if !all && spec == "" {
+		javaHome, _ := env.GetJavaHome()
+		if javaHome == "" {
+			javaHome = os.Getenv("JAVA_HOME")
+		}
+		if javaHome == "" {
+			return nil, errors.New("JAVA_HOME is not set; pass a version or --all")
+		}
+		return []java.Version{{Version: detector.GetVersion(javaHome), Path: javaHome}}, nil
+	}
+
+	versions, err := detector.FindAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to find Java installations: %w", err)
+	}
+	if all {
+		if len(versions) == 0 {
+			return nil, errors.New("no Java installations found")
+		}
+		return versions, nil
+	}
+
+	var matches []java.Version
+	for _, v := range versions {
+		if strings.Contains(v.Version, spec) {
+			matches = append(matches, v)
+		}
+	}
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("Java version '%s' not found", spec)
+	}
+	return matches, nil
+}
+
+// certificateName returns the common name of a certificate's subject, or the
+// whole subject when it has none
+func certificateName(cert *x509.Certificate) string {
+	if cert.Subject.CommonName != "" {
+		return cert.Subject.CommonName
+	}
+	return cert.Subject.String()
+}
+
+// auditReport is the JSON output of jv audit
+type auditReport struct {
+	Feed          string              `json:"feed"`
+	FeedUpdated   string              `json:"feed_updated,omitempty"`
+	Installations []auditInstallation `json:"installations"`
+	Affected      int                 `json:"affected"`
+}
+
+type auditInstallation struct {
+	Path            string         `json:"path"`
+	Version         string         `json:"version"`
+	Vendor          string         `json:"vendor,omitempty"`
+	Vulnerabilities []auditFinding `json:"vulnerabilities"`
+}
+
+type auditFinding struct {
+	ID        string  `json:"id"`
+	Severity  string  `json:"severity"`
+	Score     float64 `json:"score,omitempty"`
+	Component string  `json:"component,omitempty"`
+	FixedIn   string  `json:"fixed_in,omitempty"` // Empty when the release gets no fix
+	Advisory  string  `json:"advisory,omitempty"`
+	URL       string  `json:"url,omitempty"`
+}
+
+// handleAudit checks every detected Java installation against a vulnerability
+// feed. It exits with 1 when an installation is affected at or above
+// --fail-on (never with --fail-on none) and with 2 when the audit cannot be run.
+func handleAudit() {
+	fs := flag.NewFlagSet("audit", flag.ContinueOnError)
+	feedSource := fs.String("feed", "", "URL or file of the vulnerability feed (default: audit_feed from jv.json)")
+	jsonOutput := fs.Bool("json", false, "print the report as JSON")
+	failOn := fs.String("fail-on", "low", "lowest severity that fails the audit: critical, high, medium or low; none never fails")
+	if err := fs.Parse(os.Args[2:]); err != nil {
+		os.Exit(2)
+	}
+
+	auditError := func(msg string) {
+		if *jsonOutput {
+			fmt.Fprintln(os.Stderr, "Error: "+msg)
+		} else {
+			fmt.Println(errorStyle.Render("Error: " + msg))
+		}
+		os.Exit(2)
+	}
+
+	if audit.SeverityRank(*failOn) == len(audit.Severities) {
+		auditError(fmt.Sprintf("unknown severity %q (use critical, high, medium, low or none)", *failOn))
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		auditError("loading config: " + err.Error())
+	}
+	source := *feedSource
+	if source == "" {
+		source = cfg.AuditFeed
+	}
+	if source == "" {
+		auditError("no vulnerability feed; pass --feed <url|file> or set audit_feed in jv.json")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if *jsonOutput {
+		installer.SetProgressMode(installer.ProgressNone)
+	}
+	var feed *audit.Feed
+	var loadErr error
+	spinnerErr := installer.WithSpinner(ctx, "Loading vulnerability feed...", func(ctx context.Context) error {
+		feed, loadErr = audit.Load(ctx, source, cfg.GetCatalogDir())
+		return nil
+	})
+	if spinnerErr != nil {
+		loadErr = spinnerErr
+	}
+	if loadErr != nil {
+		auditError(loadErr.Error())
+	}
+	if !feed.CachedAt.IsZero() {
+		msg := fmt.Sprintf("Feed unreachable, using the copy fetched %s", feed.CachedAt.Format("2006-01-02 15:04"))
+		if *jsonOutput {
+			fmt.Fprintln(os.Stderr, "Warning: "+msg)
+		} else {
+			fmt.Println(theme.WarningMessage(msg))
+		}
+	}
+
+	detector := java.NewDetector()
+	var versions []java.Version
+	if *jsonOutput {
+		versions, err = detector.FindAll()
+	} else {
+		java.WithScanner(func() error {
+			versions, err = detector.FindAll()
+			return nil
+		})
+	}
+	if err != nil {
+		auditError("finding Java versions: " + err.Error())
+	}
+
+	report := auditReport{Feed: feed.Source, FeedUpdated: feed.Updated, Installations: []auditInstallation{}}
+	failed := false
+	for _, v := range versions {
+		installation := auditInstallation{
+			Path:            v.Path,
+			Version:         javaFullVersion(v),
+			Vendor:          javaVendor(cfg, v),
+			Vulnerabilities: []auditFinding{},
+		}
+
+		for _, finding := range feed.Check(installation.Version) {
+			installation.Vulnerabilities = append(installation.Vulnerabilities, auditFinding{
+				ID:        finding.ID,
+				Severity:  finding.Level(),
+				Score:     finding.Score,
+				Component: finding.Component,
+				FixedIn:   finding.FixedIn,
+				Advisory:  finding.Advisory,
+				URL:       finding.URL,
+			})
+			failed = failed || audit.Fails(finding.Level(), *failOn)
+		}
+		if len(installation.Vulnerabilities) > 0 {
+			report.Affected++
+		}
+		report.Installations = append(report.Installations, installation)
+	}
+
+	if *jsonOutput {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			auditError(err.Error())
+		}
+		fmt.Println(string(data))
+	} else {
+		printAuditReport(report)
+	}
+
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// printAuditReport prints the affected installations with one table each
+func printAuditReport(report auditReport) {
+	fmt.Println(titleStyle.Render("Vulnerability Audit"))
+	fmt.Println()
+	feed := report.Feed
+	if report.FeedUpdated != "" {
+		feed += " (" + report.FeedUpdated + ")"
+	}
+	fmt.Printf("%s %s\n", theme.LabelStyle.Render("Feed:"), theme.PathStyle.Render(feed))
+	fmt.Println()
+
+	if len(report.Installations) == 0 {
+		fmt.Println(warningStyle.Render("No Java installations found."))
+		return
+	}
+
+	headerStyle := theme.TableHeader
+	cellStyle := theme.TableCell
+	tableStyle := theme.TableStyle
+
+	for _, installation := range report.Installations {
+		label := fmt.Sprintf("Java %s", installation.Version)
+		if len(installation.Vulnerabilities) == 0 {
+			fmt.Printf("%s %s\n", theme.SuccessMessage(label), theme.Faint.Render(installation.Path))
+			continue
+		}
+
+		fmt.Printf("%s %s\n", theme.ErrorMessage(fmt.Sprintf("%s: %d vulnerabilities", label, len(installation.Vulnerabilities))), theme.Faint.Render(installation.Path))
+		var rows []string
+		rows = append(rows, lipgloss.JoinHorizontal(lipgloss.Left,
+			headerStyle.Width(18).Render("CVE"),
+			headerStyle.Width(11).Render("Severity"),
+			headerStyle.Width(7).Render("Score"),
+			headerStyle.Width(28).Render("Component"),
+			headerStyle.Render("Fixed in"),
+		))
+		for _, finding := range installation.Vulnerabilities {
+			severityStyle := theme.InfoStyle
+			switch finding.Severity {
+			case "critical", "high":
+				severityStyle = theme.ErrorStyle
+			case "medium":
+				severityStyle = theme.WarningStyle
+			}
+			score := ""
+			if finding.Score > 0 {
+				score = strconv.FormatFloat(finding.Score, 'f', 1, 64)
+			}
+			fixed := finding.FixedIn
+			if fixed == "" {
+				fixed = theme.ErrorStyle.Render("no fix (end of life)")
+			}
+			rows = append(rows, lipgloss.JoinHorizontal(lipgloss.Left,
+				cellStyle.Width(18).Render(finding.ID),
+				severityStyle.Width(11).Render(finding.Severity),
+				cellStyle.Width(7).Render(score),
+				cellStyle.Width(28).Render(finding.Component),
+				cellStyle.Render(fixed),
+			))
+		}
+		fmt.Println(tableStyle.Render(lipgloss.JoinVertical(lipgloss.Left, rows...)))
+	}
+
+	fmt.Println()
+	if report.Affected == 0 {
+		fmt.Println(theme.SuccessMessage(fmt.Sprintf("No known vulnerabilities in %d installation(s)", len(report.Installations))))
+		return
+	}
+	fmt.Println(theme.WarningMessage(fmt.Sprintf("%d of %d installation(s) affected by known vulnerabilities", report.Affected, len(report.Installations))))
+	fmt.Println(theme.Faint.Render("  Install the fixed version with 'jv install <version>'"))
+}
+
+// handleSbom writes a CycloneDX or SPDX document describing every detected
+// Java runtime, with the provenance jv recorded for the ones it installed
+func handleSbom() {
+	fs := flag.NewFlagSet("sbom", flag.ContinueOnError)
+	formatName := fs.String("format", "cyclonedx", "document format: cyclonedx or spdx")
+	output := fs.String("output", "", "file to write the document to (default: standard output)")
+	if err := fs.Parse(os.Args[2:]); err != nil {
+		os.Exit(1)
+	}
+
+	format, err := sbom.ParseFormat(*formatName)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, errorStyle.Render("Error: "+err.Error()))
+		os.Exit(1)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, errorStyle.Render("Error loading config: "+err.Error()))
+		os.Exit(1)
+	}
+
+	versions, err := java.NewDetector().FindAll()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, errorStyle.Render("Error finding Java versions: "+err.Error()))
+		os.Exit(1)
+	}
+
+	vendors := lifecycle.Embedded()
+	doc := sbom.Document{ToolVersion: Version, Created: time.Now()}
+	for _, v := range versions {
+		runtime := sbom.Runtime{
+			Vendor:    vendors.VendorName(javaVendor(cfg, v)),
+			Version:   javaFullVersion(v),
+			ImageType: "jdk",
+			Arch:      v.Arch,
+			Path:      v.Path,
+		}
+		if v.IsJRE {
+			runtime.ImageType = "jre"
+		}
+		if release, err := java.ReadReleaseFile(v.Path); err == nil {
+			runtime.VendorBuild = release["IMPLEMENTOR_VERSION"]
+		}
+		if jdk := cfg.GetInstalledJDK(v.Path); jdk != nil {
+			if jdk.FullVersion != "" {
+				runtime.Version = jdk.FullVersion
+			}
+			if jdk.VendorBuild != "" {
+				runtime.VendorBuild = jdk.VendorBuild
+			}
+			if jdk.ImageType != "" {
+				runtime.ImageType = jdk.ImageType
+			}
+			runtime.InstalledAt = jdk.InstalledAt
+			runtime.SourceURL = jdk.SourceURL
+			runtime.Checksum = jdk.Checksum
+			runtime.Signature = jdk.Signature
+		}
+		doc.Runtimes = append(doc.Runtimes, runtime)
+	}
+
+	data, err := sbom.Encode(doc, format)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, errorStyle.Render("Error: "+err.Error()))
+		os.Exit(1)
+	}
+
+	if *output == "" {
+		fmt.Println(string(data))
+		return
+	}
+	if err := os.WriteFile(*output, append(data, '\n'), 0644); err != nil {
+		fmt.Println(errorStyle.Render("Error writing SBOM: " + err.Error()))
+		os.Exit(1)
+	}
+	fmt.Println(theme.SuccessMessage(fmt.Sprintf("Wrote %s SBOM of %d Java runtime(s) to %s", format, len(doc.Runtimes), *output)))
+}
+
+// verifyListLimit is the number of paths jv verify lists per kind of problem
+const verifyListLimit = 20
+
+// handleVerify checks installed JDKs against the file manifests recorded when
+// jv installed them and reports missing, modified and extra files
+func handleVerify() {
+	fs := flag.NewFlagSet("verify", flag.ContinueOnError)
+	all := fs.Bool("all", false, "verify every detected Java installation")
+	reinstall := fs.Bool("reinstall", false, "restore JDKs with problems from the download cache")
+	update := fs.Bool("update", false, "record the current files as the manifest, e.g. after a deliberate change")
+	args, err := parseFlags(fs, os.Args[2:])
+	if err != nil {
+		os.Exit(1)
+	}
+	if len(args) > 1 || (*all && len(args) == 1) || (*reinstall && *update) {
+		fmt.Println(errorStyle.Render("Usage: jv verify [--all] [--reinstall | --update] [version]"))
+		fmt.Println(infoStyle.Render("Without --all or a version the current JAVA_HOME is verified."))
+		os.Exit(1)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Println(errorStyle.Render("Error loading config: " + err.Error()))
+		os.Exit(1)
+	}
+	spec := ""
+	if len(args) == 1 {
+		spec = args[0]
+	}
+	targets, err := javaTargets(*all, spec)
+	if err != nil {
+		fmt.Println(errorStyle.Render("Error: " + err.Error()))
+		os.Exit(1)
+	}
+
+	// Ctrl+C stops hashing; a reinstall in progress is rolled back
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	exitIfCancelled := func() {
+		if ctx.Err() != nil {
+			fmt.Println(theme.WarningMessage("Verification cancelled"))
+			os.Exit(130)
+		}
+	}
+
+	manifestDir := cfg.GetManifestDir()
+	var inst *installer.Installer // Created for the first reinstall
+	failed := 0
+	for _, target := range targets {
+		label := fmt.Sprintf("Java %s (%s)", target.Version, target.Path)
+
+		if *update {
+			var m *manifest.Manifest
+			var buildErr error
+			installer.WithSpinner(ctx, "Hashing files...", func(ctx context.Context) error {
+				m, buildErr = manifest.Build(ctx, target.Path)
+				return nil
+			})
+			exitIfCancelled()
+			if buildErr == nil {
+				buildErr = m.Save(manifestDir)
+			}
+			if buildErr != nil {
+				fmt.Println(theme.ErrorMessage(fmt.Sprintf("%s: %v", label, buildErr)))
+				failed++
+				continue
+			}
+			fmt.Println(theme.SuccessMessage(fmt.Sprintf("%s: recorded %d files", label, len(m.Files))))
+			continue
+		}
+
+		m, err := manifest.Load(manifestDir, target.Path)
+		if errors.Is(err, manifest.ErrNotRecorded) {
+			fmt.Println(theme.InfoMessage(fmt.Sprintf("%s: no manifest recorded, skipped (record one with 'jv verify --update')", label)))
+			continue
+		}
+		if err != nil {
+			fmt.Println(theme.ErrorMessage(fmt.Sprintf("%s: %v", label, err)))
+			failed++
+			continue
+		}
+
+		var report *manifest.Report
+		var verifyErr error
+		installer.WithSpinner(ctx, fmt.Sprintf("Verifying %d files...", len(m.Files)), func(ctx context.Context) error {
+			report, verifyErr = m.Verify(ctx)
+			return nil
+		})
+		exitIfCancelled()
+		if verifyErr != nil {
+			fmt.Println(theme.ErrorMessage(fmt.Sprintf("%s: %v", label, verifyErr)))
+			failed++
+			continue
+		}
+		if report.Clean() {
+			fmt.Println(theme.SuccessMessage(fmt.Sprintf("%s: all %d files intact", label, len(m.Files))))
+			continue
+		}
+
+		fmt.Println(theme.ErrorMessage(fmt.Sprintf("%s: %d missing, %d modified, %d extra file(s)",
+			label, len(report.Missing), len(report.Modified), len(report.Extra))))
+		printVerifyPaths("Missing", report.Missing)
+		printVerifyPaths("Modified", report.Modified)
+		printVerifyPaths("Extra", report.Extra)
+
+		if !*reinstall {
+			failed++
+			continue
+		}
+		if inst == nil {
+			if inst, err = installer.NewInstaller(env.IsAdmin(), installer.Options{}); err != nil {
+				fmt.Println(errorStyle.Render("Error: " + err.Error()))
+				os.Exit(1)
+			}
+		}
+		fmt.Println()
+		if err := inst.Reinstall(ctx, target.Path); err != nil {
+			exitIfCancelled()
+			fmt.Println(theme.ErrorMessage(fmt.Sprintf("%s: reinstall failed: %v", label, err)))
+			failed++
+			continue
+		}
+		fmt.Println(theme.SuccessMessage(fmt.Sprintf("%s: restored from its archive", label)))
+		fmt.Println()
+	}
+
+	if failed > 0 {
+		fmt.Println()
+		switch {
+		case *reinstall:
+			fmt.Println(theme.Faint.Render("Note: system-wide installations can only be reinstalled as Administrator."))
+		case !*update:
+			fmt.Println(theme.Faint.Render("Restore a JDK from the download cache with 'jv verify --reinstall <version>',"))
+			fmt.Println(theme.Faint.Render("or accept deliberate changes with 'jv verify --update <version>'."))
+		}
+		os.Exit(1)
+	}
+}
+
+// printVerifyPaths lists the files with one kind of problem, at most verifyListLimit of them
+func printVerifyPaths(kind string, paths []string) {
+	if len(paths) == 0 {
+		return
+	}
+	fmt.Printf("  %s\n", theme.LabelStyle.Render(fmt.Sprintf("%s (%d):", kind, len(paths))))
+	for idx, path := range paths {
+		if idx == verifyListLimit {
+			fmt.Println(theme.Faint.Render(fmt.Sprintf("    ... and %d more", len(paths)-idx)))
+			break
+		}
+		fmt.Printf("    %s\n", theme.PathStyle.Render(path))
+	}
+}
+
+func handleRepair() {
+	// Themed header
+	header := theme.Title.Padding(0, 2).Render("Java Version Switcher - Auto Repair")
+	fmt.Println(theme.TitleBox.Render(header))
+	fmt.Println()
+
+	isAdmin := env.IsAdmin()
+	if !isAdmin {
+		fmt.Println(theme.WarningMessage("Not running as Administrator"))
+		fmt.Println(theme.Faint.Render("   Some repairs require administrator privileges."))
+		fmt.Println()
+	}
+
+	detector := java.NewDetector()
+
+	// Step 1: Detect all issues
+	fmt.Println(theme.LabelStyle.Render("Scanning for issues..."))
+	fmt.Println()
+
+	// Find Java installations first
+	versions, err := detector.FindAll()
+	if err != nil {
+		fmt.Println(theme.ErrorMessage(fmt.Sprintf("Error finding Java versions: %v", err)))
+		os.Exit(1)
+	}
+
+	if len(versions) == 0 {
+		fmt.Println(theme.ErrorMessage("No Java installations found."))
+		fmt.Println(theme.Faint.Render("Please install Java first: jv install"))
+		os.Exit(1)
+	}
+
+	// Detect all issues
+	issues := []RepairIssue{}
+	currentJavaHome := os.Getenv("JAVA_HOME")
+
+	// Issue 1: JAVA_HOME not set or invalid
+	if currentJavaHome == "" {
+		issues = append(issues, RepairIssue{
+			ID:            "java_home_not_set",
+			Description:   "JAVA_HOME is not set",
+			RequiresAdmin: true,
+			CanFix:        isAdmin,
+		})
+	} else if !detector.IsValidJavaPath(currentJavaHome) {
+		issues = append(issues, RepairIssue{
+			ID:            "java_home_invalid",
+			Description:   fmt.Sprintf("JAVA_HOME is invalid: %s", currentJavaHome),
+			RequiresAdmin: true,
+			CanFix:        isAdmin,
+		})
+	}
+
+	// Issue 2: PATH doesn't contain %JAVA_HOME%\bin (check resolved <JAVA_HOME>\bin exactly)
+	pathEnv := os.Getenv("Path")
+	hasJavaHomeInPath := false
+	if currentJavaHome != "" {
+		expectedBin := strings.ToLower(strings.TrimRight(filepath.Join(currentJavaHome, "bin"), "\\"))
+		for _, entry := range strings.Split(pathEnv, ";") {
+			e := strings.TrimSpace(strings.Trim(entry, "\""))
+			if e == "" {
+				continue
+			}
+			eLower := strings.ToLower(strings.TrimRight(e, "\\"))
+			if eLower == expectedBin {
+				hasJavaHomeInPath = true
+				break
+			}
+		}
+	}
+	if !hasJavaHomeInPath && currentJavaHome != "" {
+		issues = append(issues, RepairIssue{
+			ID:            "path_missing_java_home",
+			Description:   "%JAVA_HOME%\\bin is not in PATH",
+			RequiresAdmin: true,
+			CanFix:        isAdmin,
+		})
+	}
+
+	// Issue 3: Config file problems
+	if _, err := config.Load(); err != nil {
+		issues = append(issues, RepairIssue{
+			ID:            "config_error",
+			Description:   fmt.Sprintf("Configuration file error: %v", err),
+			RequiresAdmin: false,
+			CanFix:        true,
+		})
+	}
+
+	// No issues found
+	if len(issues) == 0 {
+		fmt.Println(successStyle.Render("✓ No issues found - your environment is properly configured!"))
+		return
+	}
+
+	// Show issues
+	fmt.Printf("%s %d issue(s):\n", theme.LabelStyle.Render("Found"), len(issues))
+	for i, issue := range issues {
+		adminMarker := ""
+		if issue.RequiresAdmin {
+			adminMarker = " [Requires Admin]"
+		}
+		if !issue.CanFix {
+			adminMarker += " [Cannot Fix]"
+		}
+		fmt.Printf("  %d. %s%s\n", i+1, theme.WarningStyle.Render(issue.Description), adminMarker)
+	}
+	fmt.Println()
+
+	// Filter fixable issues
+	fixableIssues := []RepairIssue{}
+	for _, issue := range issues {
+		if issue.CanFix {
+			fixableIssues = append(fixableIssues, issue)
+		}
+	}
+
+	if len(fixableIssues) == 0 {
+		fmt.Println(theme.ErrorMessage("No fixable issues (some require administrator privileges)"))
+		fmt.Println(theme.Faint.Render("  Run as administrator to fix all issues"))
 		os.Exit(1)
 	}
 
@@ -1088,7 +2289,7 @@ func handleRepair() {
 				continue
 			}
 
-			if err := env.SetJavaHome(target.Path); err != nil {
+			if err := repairJavaHome(target, currentJavaHome); err != nil {
 				fmt.Printf("  %s %v\n", theme.ErrorMessage("Failed to set JAVA_HOME:"), err)
 				continue
 			}
@@ -1110,7 +2311,8 @@ func handleRepair() {
 				}
 				targetPath = t.Path
 			}
-			if err := env.SetJavaHome(targetPath); err != nil {
+			target := &java.Version{Version: detector.GetVersion(targetPath), Path: targetPath}
+			if err := repairJavaHome(target, currentJavaHome); err != nil {
 				fmt.Printf("  %s %v\n", theme.ErrorMessage("Failed to update PATH:"), err)
 				continue
 			}
@@ -1196,6 +2398,24 @@ func printUsage() {
 	fmt.Printf("  %s             %s\n",
 		commandStyle.Render("repair"),
 		descStyle.Render("Automatically fix configuration issues"))
+	fmt.Printf("  %s        %s\n",
+		commandStyle.Render("cache <cmd>"),
+		descStyle.Render("Manage downloaded archives (list, prune, clean)"))
+	fmt.Printf("  %s    %s\n",
+		commandStyle.Render("catalog refresh"),
+		descStyle.Render("Update the offline catalog of available versions"))
+	fmt.Printf("  %s        %s\n",
+		commandStyle.Render("certs <cmd>"),
+		descStyle.Render("Import CA certificates into JDK truststores (import, list)"))
+	fmt.Printf("  %s              %s\n",
+		commandStyle.Render("audit"),
+		descStyle.Render("Check installed JDKs against a vulnerability feed"))
+	fmt.Printf("  %s               %s\n",
+		commandStyle.Render("sbom"),
+		descStyle.Render("Export installed runtimes as CycloneDX or SPDX JSON"))
+	fmt.Printf("  %s             %s\n",
+		commandStyle.Render("verify"),
+		descStyle.Render("Check installed JDKs for missing or modified files"))
 	fmt.Println()
 
 	fmt.Println(categoryStyle.Render("VERSION MANAGEMENT"))
@@ -1249,6 +2469,7 @@ func printUsage() {
 	fmt.Println("  " + theme.Code.Render("jv switch") + "                # Interactive switcher")
 	fmt.Println("  " + theme.Code.Render("jv use 17") + "                # Switch to Java 17")
 	fmt.Println("  " + theme.Code.Render("jv install") + "               # Install Java interactively")
+	fmt.Println("  " + theme.Code.Render("jv install 17.0.8+7") + "      # Install a specific build")
 	fmt.Println("  " + theme.Code.Render("jv add C:\\custom\\jdk-21") + "  # Add custom installation")
 	fmt.Println("  " + theme.Code.Render("jv doctor") + "                # Check system health")
 	fmt.Println()